@@ -0,0 +1,77 @@
+package actions
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"location_service_v1/ls_v2/models"
+	"location_service_v1/ls_v2/ratelimit"
+	"location_service_v1/ls_v2/service"
+	"strings"
+
+	"github.com/gobuffalo/buffalo"
+)
+
+// apiJSON makes the API answer JSON whatever the Accept header says, so
+// that errorResponses renders its errors as JSON too.
+func apiJSON(next buffalo.Handler) buffalo.Handler {
+	return func(c buffalo.Context) error {
+		c.Request().Header.Set("Accept", "application/json")
+		return next(c)
+	}
+}
+
+// apiAuthenticate lets through the requests with a valid bearer token in
+// the Authorization header and sets it as "api_token" in the context. The
+// rate limiter that runs after it counts the requests of the token.
+func apiAuthenticate(apiTokensService *service.APITokensService) buffalo.MiddlewareFunc {
+	return func(next buffalo.Handler) buffalo.Handler {
+		return func(c buffalo.Context) error {
+			secret := bearerToken(c.Request().Header.Get("Authorization"))
+			if secret == "" {
+				c.Response().Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+				return models.UnauthorizedError("missing bearer token")
+			}
+
+			token, err := apiTokensService.Authenticate(c, secret)
+			if err != nil {
+				c.Response().Header().Set("WWW-Authenticate", `Bearer realm="api", error="invalid_token"`)
+				return err
+			}
+
+			c.Set("api_token", token)
+			c.Set(ratelimit.ClientKey, "token:"+token.ID.String())
+			return next(c)
+		}
+	}
+}
+
+// bearerToken is the secret of an "Authorization: Bearer <secret>" header.
+func bearerToken(header string) string {
+	parts := strings.SplitN(strings.TrimSpace(header), " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(parts[1])
+}
+
+// apiBind decodes the JSON body of an API request into the dto v. Unknown
+// keys are rejected so that misspelled fields do not go unnoticed.
+func apiBind(c buffalo.Context, v interface{}) error {
+	dec := json.NewDecoder(c.Request().Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return models.BadRequestError(fmt.Errorf("invalid body: %v", err))
+	}
+	return nil
+}
+
+// apiRejectFields answers 400 to the fields parameter of the HTML
+// resources. The API bodies have a fixed shape, so the fields left out of
+// the query would read as real zero values.
+func apiRejectFields(c buffalo.Context) error {
+	if strings.TrimSpace(c.Param("fields")) != "" {
+		return models.BadRequestError(errors.New("fields is not supported by the API, its bodies always have every field"))
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package actions
+
+import (
+	"encoding/json"
+	"location_service_v1/ls_v2/dto"
+	"location_service_v1/ls_v2/models"
+	"location_service_v1/ls_v2/repository"
+	"location_service_v1/ls_v2/service"
+	"net/http"
+
+	"github.com/gobuffalo/buffalo"
+)
+
+// APICompaniesResource serves the companies of the JSON API under /api/v1
+type APICompaniesResource struct {
+	companiesService *service.CompaniesService
+}
+
+// NewAPICompaniesResource is a
+func NewAPICompaniesResource(companiesService *service.CompaniesService) *APICompaniesResource {
+	return &APICompaniesResource{
+		companiesService: companiesService,
+	}
+}
+
+// ParamKey keeps the "company_id" parameter the repositories read.
+func (v APICompaniesResource) ParamKey() string {
+	return "company_id"
+}
+
+// List gets the companies with the same filters, sorting and pagination as
+// the HTML list. This function is mapped to the path GET /api/v1/companies
+func (v APICompaniesResource) List(c buffalo.Context) error {
+	if err := apiRejectFields(c); err != nil {
+		return err
+	}
+	if repository.CursorRequested(c.Params()) {
+		companies, page, err := v.companiesService.Scroll(c)
+		if err != nil {
+			return err
+		}
+		return c.Render(http.StatusOK, r.JSON(cursorList(c, dto.NewCompanies(*companies), page)))
+	}
+
+	companies, q, err := v.companiesService.List(c)
+	if err != nil {
+		return err
+	}
+	return c.Render(http.StatusOK, r.JSON(pageList(c, dto.NewCompanies(*companies), q.Paginator)))
+}
+
+// Show gets one company. This function is mapped to the path
+// GET /api/v1/companies/{company_id}
+func (v APICompaniesResource) Show(c buffalo.Context) error {
+	if err := apiRejectFields(c); err != nil {
+		return err
+	}
+	company, err := v.companiesService.Show(c)
+	if err != nil {
+		return err
+	}
+	if notModified(c, company.Version) {
+		return c.Render(http.StatusNotModified, nil)
+	}
+	return c.Render(http.StatusOK, r.JSON(dto.NewCompany(*company)))
+}
+
+// Create adds a company. This function is mapped to the path
+// POST /api/v1/companies
+func (v APICompaniesResource) Create(c buffalo.Context) error {
+	in := dto.CompanyInput{}
+	if err := apiBind(c, &in); err != nil {
+		return err
+	}
+
+	company := &models.Company{}
+	in.Apply(company)
+
+	verrs, err := v.companiesService.Insert(c, company)
+	if err != nil {
+		return err
+	}
+	if verrs.HasAny() {
+		return models.ValidationError(verrs)
+	}
+
+	c.Response().Header().Set("Location", "/api/v1/companies/"+company.ID.String())
+	setETag(c, company.Version)
+	return c.Render(http.StatusCreated, r.JSON(dto.NewCompany(*company)))
+}
+
+// Update replaces a company. This function is mapped to the path
+// PUT /api/v1/companies/{company_id}
+func (v APICompaniesResource) Update(c buffalo.Context) error {
+	company, err := v.companiesService.Edit(c)
+	if err != nil {
+		return err
+	}
+
+	in := dto.CompanyInput{}
+	if err := apiBind(c, &in); err != nil {
+		return err
+	}
+	in.Apply(company)
+
+	verrs, err := v.companiesService.Save(c, company)
+	if err != nil {
+		return err
+	}
+	if verrs.HasAny() {
+		return models.ValidationError(verrs)
+	}
+
+	setETag(c, company.Version)
+	return c.Render(http.StatusOK, r.JSON(dto.NewCompany(*company)))
+}
+
+// Patch changes only the fields of a company that the body sends, as a JSON
+// Merge Patch or, with the application/json-patch+json type, a JSON Patch
+// of the CompanyInput keys. This function is mapped to the path
+// PATCH /api/v1/companies/{company_id}
+func (v APICompaniesResource) Patch(c buffalo.Context) error {
+	company, err := v.companiesService.Edit(c)
+	if err != nil {
+		return err
+	}
+
+	doc, err := json.Marshal(dto.NewCompanyInput(*company))
+	if err != nil {
+		return err
+	}
+	in := dto.CompanyInput{}
+	if err := repository.ApplyPatch(c, doc, &in); err != nil {
+		return err
+	}
+	in.Apply(company)
+
+	verrs, err := v.companiesService.Save(c, company)
+	if err != nil {
+		return err
+	}
+	if verrs.HasAny() {
+		return models.ValidationError(verrs)
+	}
+
+	setETag(c, company.Version)
+	return c.Render(http.StatusOK, r.JSON(dto.NewCompany(*company)))
+}
+
+// Destroy deletes a company. This function is mapped to the path
+// DELETE /api/v1/companies/{company_id}
+func (v APICompaniesResource) Destroy(c buffalo.Context) error {
+	if _, err := v.companiesService.Destroy(c); err != nil {
+		return err
+	}
+	return c.Render(http.StatusNoContent, nil)
+}
@@ -0,0 +1,257 @@
+package actions
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"location_service_v1/ls_v2/dto"
+	"location_service_v1/ls_v2/models"
+	"location_service_v1/ls_v2/repository"
+	"location_service_v1/ls_v2/service"
+	"net/http"
+
+	"github.com/gobuffalo/buffalo"
+)
+
+// APIPointsResource serves the points of the JSON API under /api/v1
+type APIPointsResource struct {
+	pointsService *service.PointsService
+}
+
+// NewAPIPointsResource is a
+func NewAPIPointsResource(pointsService *service.PointsService) *APIPointsResource {
+	return &APIPointsResource{
+		pointsService: pointsService,
+	}
+}
+
+// ParamKey keeps the "point_id" parameter the repositories read.
+func (v APIPointsResource) ParamKey() string {
+	return "point_id"
+}
+
+// List gets the points with the same filters, sorting and pagination as
+// the HTML list. This function is mapped to the path GET /api/v1/points
+func (v APIPointsResource) List(c buffalo.Context) error {
+	if err := apiRejectFields(c); err != nil {
+		return err
+	}
+	if repository.CursorRequested(c.Params()) {
+		points, page, err := v.pointsService.Scroll(c)
+		if err != nil {
+			return err
+		}
+		return c.Render(http.StatusOK, r.JSON(cursorList(c, dto.NewPoints(*points), page)))
+	}
+
+	points, q, err := v.pointsService.List(c)
+	if err != nil {
+		return err
+	}
+	return c.Render(http.StatusOK, r.JSON(pageList(c, dto.NewPoints(*points), q.Paginator)))
+}
+
+// Changes gets a page of the change feed of points. This function is
+// mapped to the path GET /api/v1/points/changes
+func (v APIPointsResource) Changes(c buffalo.Context) error {
+	feed, err := v.pointsService.Changes(c)
+	if err != nil {
+		return err
+	}
+	return c.Render(http.StatusOK, r.JSON(dto.NewPointChangeFeed(*feed)))
+}
+
+// Show gets one point. This function is mapped to the path
+// GET /api/v1/points/{point_id}
+func (v APIPointsResource) Show(c buffalo.Context) error {
+	if err := apiRejectFields(c); err != nil {
+		return err
+	}
+	point, err := v.pointsService.Show(c)
+	if err != nil {
+		return err
+	}
+	if notModified(c, point.Version) {
+		return c.Render(http.StatusNotModified, nil)
+	}
+	return c.Render(http.StatusOK, r.JSON(dto.NewPoint(*point)))
+}
+
+// Create adds a point. This function is mapped to the path
+// POST /api/v1/points
+func (v APIPointsResource) Create(c buffalo.Context) error {
+	in := dto.PointInput{}
+	if err := apiBind(c, &in); err != nil {
+		return err
+	}
+
+	point := &models.Point{}
+	in.Apply(point)
+
+	verrs, err := v.pointsService.Insert(c, point)
+	if err != nil {
+		return err
+	}
+	if verrs.HasAny() {
+		return models.ValidationError(verrs)
+	}
+
+	c.Response().Header().Set("Location", "/api/v1/points/"+point.ID.String())
+	setETag(c, point.Version)
+	return c.Render(http.StatusCreated, r.JSON(dto.NewPoint(*point)))
+}
+
+// Update replaces a point. This function is mapped to the path
+// PUT /api/v1/points/{point_id}
+func (v APIPointsResource) Update(c buffalo.Context) error {
+	point, err := v.pointsService.Edit(c)
+	if err != nil {
+		return err
+	}
+
+	in := dto.PointInput{}
+	if err := apiBind(c, &in); err != nil {
+		return err
+	}
+	in.Apply(point)
+
+	verrs, err := v.pointsService.Save(c, point)
+	if err != nil {
+		return err
+	}
+	if verrs.HasAny() {
+		return models.ValidationError(verrs)
+	}
+
+	setETag(c, point.Version)
+	return c.Render(http.StatusOK, r.JSON(dto.NewPoint(*point)))
+}
+
+// Patch changes only the fields of a point that the body sends, as a JSON
+// Merge Patch or, with the application/json-patch+json type, a JSON Patch
+// of the PointInput keys. This function is mapped to the path
+// PATCH /api/v1/points/{point_id}
+func (v APIPointsResource) Patch(c buffalo.Context) error {
+	point, err := v.pointsService.Edit(c)
+	if err != nil {
+		return err
+	}
+
+	doc, err := json.Marshal(dto.NewPointInput(*point))
+	if err != nil {
+		return err
+	}
+	in := dto.PointInput{}
+	if err := repository.ApplyPatch(c, doc, &in); err != nil {
+		return err
+	}
+	in.Apply(point)
+
+	verrs, err := v.pointsService.Save(c, point)
+	if err != nil {
+		return err
+	}
+	if verrs.HasAny() {
+		return models.ValidationError(verrs)
+	}
+
+	setETag(c, point.Version)
+	return c.Render(http.StatusOK, r.JSON(dto.NewPoint(*point)))
+}
+
+// Destroy deletes a point. This function is mapped to the path
+// DELETE /api/v1/points/{point_id}
+func (v APIPointsResource) Destroy(c buffalo.Context) error {
+	if _, err := v.pointsService.Destroy(c); err != nil {
+		return err
+	}
+	return c.Render(http.StatusNoContent, nil)
+}
+
+// pointsBulkResult is the outcome of one operation of
+// POST /api/v1/points/bulk. Status is the status the operation would have
+// had on its own.
+type pointsBulkResult struct {
+	Index  int              `json:"index"`
+	Op     string           `json:"op"`
+	Status int              `json:"status"`
+	Point  *dto.Point       `json:"point,omitempty"`
+	Error  *dto.ErrorDetail `json:"error,omitempty"`
+}
+
+// pointsBulkResponse is the body of POST /api/v1/points/bulk. Committed is
+// false when an atomic batch was rolled back.
+type pointsBulkResponse struct {
+	Mode      string             `json:"mode"`
+	Committed bool               `json:"committed"`
+	Succeeded int                `json:"succeeded"`
+	Failed    int                `json:"failed"`
+	Results   []pointsBulkResult `json:"results"`
+}
+
+// Bulk creates, updates and deletes many points in one request and reports
+// the result of each operation. The points of the operations have the
+// PointInput keys. An atomic batch with a failed operation answers 422,
+// which rolls the transaction back. This function is mapped to the path
+// POST /api/v1/points/bulk
+func (v APIPointsResource) Bulk(c buffalo.Context) error {
+	bulk := &models.PointsBulk{}
+	if err := apiBind(c, bulk); err != nil {
+		return err
+	}
+	if err := bulk.Validate(); err != nil {
+		return err
+	}
+
+	results, err := v.pointsService.Bulk(c, bulk, decodePointInput)
+	if err != nil {
+		return err
+	}
+
+	res := pointsBulkResponse{
+		Mode:      bulk.Mode,
+		Failed:    results.Failed(),
+		Committed: bulk.Mode == models.BulkBestEffort || results.Failed() == 0,
+		Results:   make([]pointsBulkResult, len(results)),
+	}
+	res.Succeeded = len(results) - res.Failed
+
+	for i, result := range results {
+		item := pointsBulkResult{Index: result.Index, Op: result.Op, Status: http.StatusOK}
+		if result.Point != nil {
+			point := dto.NewPoint(*result.Point)
+			item.Point = &point
+		}
+		if result.Op == models.BulkCreate {
+			item.Status = http.StatusCreated
+		}
+		if result.Err != nil {
+			body := newErrorBody(result.Err)
+			if body.Error.Status >= http.StatusInternalServerError {
+				c.Logger().Error(result.Err)
+			}
+			item.Status = body.Error.Status
+			item.Error = &body.Error
+		}
+		res.Results[i] = item
+	}
+
+	status := http.StatusOK
+	if !res.Committed {
+		status = http.StatusUnprocessableEntity
+	}
+	return c.Render(status, r.JSON(res))
+}
+
+// decodePointInput copies the PointInput of a bulk operation to p. The
+// keys it leaves out keep the values of p.
+func decodePointInput(raw json.RawMessage, p *models.Point) error {
+	in := dto.NewPointInput(*p)
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&in); err != nil {
+		return models.BadRequestError(fmt.Errorf("invalid point: %v", err))
+	}
+	in.Apply(p)
+	return nil
+}
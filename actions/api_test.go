@@ -0,0 +1,175 @@
+package actions
+
+import (
+	"location_service_v1/ls_v2/dto"
+	"location_service_v1/ls_v2/models"
+)
+
+// apiToken creates an API token and returns its secret.
+func (as *ActionSuite) apiToken() string {
+	token, secret, err := models.NewAPIToken("test")
+	as.NoError(err)
+	as.NoError(as.DB.Create(token))
+	return secret
+}
+
+func (as *ActionSuite) Test_API_Unauthorized() {
+	res := as.JSON("/api/v1/points").Get()
+	as.Equal(401, res.Code)
+	as.Contains(res.Header().Get("WWW-Authenticate"), "Bearer")
+
+	body := dto.Error{}
+	res.Bind(&body)
+	as.Equal(401, body.Error.Status)
+
+	req := as.JSON("/api/v1/points")
+	req.Headers["Authorization"] = "Bearer ls_unknown"
+	as.Equal(401, req.Get().Code)
+}
+
+func (as *ActionSuite) Test_APIPointsResource_Create() {
+	req := as.JSON("/api/v1/points")
+	req.Headers["Authorization"] = "Bearer " + as.apiToken()
+
+	// no CSRF token is needed
+	res := req.Post(dto.PointInput{Name: "Tverskaya 7", City: "Moscow", OwnerID: 5})
+	as.Equal(201, res.Code)
+
+	point := dto.Point{}
+	res.Bind(&point)
+	as.Equal("Moscow", point.City)
+	as.Equal(5, point.OwnerID)
+
+	res = req.Post(map[string]string{"citiName": "Moscow"})
+	as.Equal(400, res.Code)
+
+	res = req.Post(dto.PointInput{})
+	as.Equal(422, res.Code)
+	body := dto.Error{}
+	res.Bind(&body)
+	as.NotEmpty(body.Error.Fields)
+}
+
+func (as *ActionSuite) Test_APIPointsResource_Show() {
+	point := &models.Point{Name: "Tverskaya 7", CityName: "Moscow"}
+	as.NoError(as.DB.Create(point))
+
+	req := as.JSON("/api/v1/points/%s", point.ID)
+	req.Headers["Authorization"] = "Bearer " + as.apiToken()
+	res := req.Get()
+	as.Equal(200, res.Code)
+	as.Contains(res.Body.String(), `"city":"Moscow"`)
+	as.NotContains(res.Body.String(), "citiName")
+
+	etag := res.Header().Get("ETag")
+	as.Equal(models.ETag(point.Version), etag)
+
+	req.Headers["If-None-Match"] = etag
+	res = req.Get()
+	as.Equal(304, res.Code)
+	as.Empty(res.Body.String())
+
+	// the API bodies have every field
+	req = as.JSON("/api/v1/points/%s?fields=name", point.ID)
+	req.Headers["Authorization"] = "Bearer " + as.apiToken()
+	as.Equal(400, req.Get().Code)
+	req = as.JSON("/api/v1/points?fields=name")
+	req.Headers["Authorization"] = "Bearer " + as.apiToken()
+	as.Equal(400, req.Get().Code)
+}
+
+func (as *ActionSuite) Test_APIPointsResource_Update_IfMatch() {
+	point := &models.Point{Name: "Tverskaya 7", CityName: "Moscow"}
+	as.NoError(as.DB.Create(point))
+
+	req := as.JSON("/api/v1/points/%s", point.ID)
+	req.Headers["Authorization"] = "Bearer " + as.apiToken()
+	req.Headers["If-Match"] = models.ETag(point.Version)
+	res := req.Put(dto.PointInput{Name: "Tverskaya 7", City: "Tver"})
+	as.Equal(200, res.Code)
+	as.Equal(models.ETag(point.Version+1), res.Header().Get("ETag"))
+
+	// the same If-Match is stale now
+	res = req.Put(dto.PointInput{Name: "Tverskaya 7", City: "Moscow"})
+	as.Equal(412, res.Code)
+	body := dto.Error{}
+	res.Bind(&body)
+	as.Equal("precondition_failed", body.Error.Code)
+
+	as.Equal(412, req.Delete().Code)
+}
+
+func (as *ActionSuite) Test_APIPointsResource_Patch() {
+	point := &models.Point{Name: "Tverskaya 7", CityName: "Moscow", OwnerID: 5}
+	as.NoError(as.DB.Create(point))
+
+	req := as.JSON("/api/v1/points/%s", point.ID)
+	req.Headers["Authorization"] = "Bearer " + as.apiToken()
+	req.Headers["Content-Type"] = "application/json-patch+json"
+	res := req.Patch([]map[string]interface{}{
+		{"op": "test", "path": "/city", "value": "Moscow"},
+		{"op": "replace", "path": "/city", "value": "Tver"},
+	})
+	as.Equal(200, res.Code)
+
+	body := dto.Point{}
+	res.Bind(&body)
+	as.Equal("Tver", body.City)
+	as.Equal(5, body.OwnerID)
+
+	res = req.Patch([]map[string]interface{}{{"op": "test", "path": "/city", "value": "Moscow"}})
+	as.Equal(409, res.Code)
+}
+
+func (as *ActionSuite) Test_APIPointsResource_Bulk() {
+	point := &models.Point{Name: "Tverskaya 7", OwnerID: 5}
+	as.NoError(as.DB.Create(point))
+
+	// a partner sends neither a session nor a CSRF token
+	req := as.JSON("/api/v1/points/bulk")
+	as.Equal(401, req.Post(map[string]interface{}{}).Code)
+	req.Headers["Authorization"] = "Bearer " + as.apiToken()
+
+	res := req.Post(map[string]interface{}{
+		"mode": models.BulkBestEffort,
+		"operations": []map[string]interface{}{
+			{"op": "create", "point": map[string]interface{}{"name": "Arbat 1", "city": "Moscow"}},
+			{"op": "create", "point": map[string]interface{}{"city": "Moscow"}},
+			{"op": "update", "id": point.ID, "point": map[string]interface{}{"address": "Tverskaya 7/2"}},
+			{"op": "create", "point": map[string]interface{}{"citiName": "Moscow"}},
+		},
+	})
+	as.Equal(200, res.Code)
+
+	body := pointsBulkResponse{}
+	res.Bind(&body)
+	as.True(body.Committed)
+	as.Equal(2, body.Succeeded)
+	as.Equal("Moscow", body.Results[0].Point.City)
+	as.Equal(422, body.Results[1].Status)
+	as.Equal("validation_failed", body.Results[1].Error.Code)
+	as.Equal(400, body.Results[3].Status)
+
+	// the update kept the fields it did not send
+	as.NoError(as.DB.Reload(point))
+	as.Equal("Tverskaya 7/2", point.Address)
+	as.Equal(5, point.OwnerID)
+
+	count, err := as.DB.Count(&models.Point{})
+	as.NoError(err)
+	as.Equal(2, count)
+
+	// one failure rolls an atomic batch back
+	res = req.Post(map[string]interface{}{
+		"operations": []map[string]interface{}{
+			{"op": "delete", "id": point.ID},
+			{"op": "delete", "id": "6ba7b810-9dad-11d1-80b4-00c04fd430c8"},
+		},
+	})
+	as.Equal(422, res.Code)
+	count, err = as.DB.Count(&models.Point{})
+	as.NoError(err)
+	as.Equal(2, count)
+
+	as.Equal(400, req.Post(map[string]interface{}{"operations": []map[string]interface{}{}}).Code)
+}
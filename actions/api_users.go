@@ -0,0 +1,157 @@
+package actions
+
+import (
+	"encoding/json"
+	"location_service_v1/ls_v2/dto"
+	"location_service_v1/ls_v2/models"
+	"location_service_v1/ls_v2/repository"
+	"location_service_v1/ls_v2/service"
+	"net/http"
+
+	"github.com/gobuffalo/buffalo"
+)
+
+// APIUsersResource serves the users of the JSON API under /api/v1
+type APIUsersResource struct {
+	usersService *service.UsersService
+}
+
+// NewAPIUsersResource is a
+func NewAPIUsersResource(usersService *service.UsersService) *APIUsersResource {
+	return &APIUsersResource{
+		usersService: usersService,
+	}
+}
+
+// ParamKey keeps the "user_id" parameter the repositories read.
+func (v APIUsersResource) ParamKey() string {
+	return "user_id"
+}
+
+// List gets the users with the same filters, sorting and pagination as
+// the HTML list. This function is mapped to the path GET /api/v1/users
+func (v APIUsersResource) List(c buffalo.Context) error {
+	if err := apiRejectFields(c); err != nil {
+		return err
+	}
+	if repository.CursorRequested(c.Params()) {
+		users, page, err := v.usersService.Scroll(c)
+		if err != nil {
+			return err
+		}
+		return c.Render(http.StatusOK, r.JSON(cursorList(c, dto.NewUsers(*users), page)))
+	}
+
+	users, q, err := v.usersService.List(c)
+	if err != nil {
+		return err
+	}
+	return c.Render(http.StatusOK, r.JSON(pageList(c, dto.NewUsers(*users), q.Paginator)))
+}
+
+// Show gets one user. This function is mapped to the path
+// GET /api/v1/users/{user_id}
+func (v APIUsersResource) Show(c buffalo.Context) error {
+	if err := apiRejectFields(c); err != nil {
+		return err
+	}
+	user, err := v.usersService.Show(c)
+	if err != nil {
+		return err
+	}
+	if notModified(c, user.Version) {
+		return c.Render(http.StatusNotModified, nil)
+	}
+	return c.Render(http.StatusOK, r.JSON(dto.NewUser(*user)))
+}
+
+// Create adds a user. This function is mapped to the path
+// POST /api/v1/users
+func (v APIUsersResource) Create(c buffalo.Context) error {
+	in := dto.UserInput{}
+	if err := apiBind(c, &in); err != nil {
+		return err
+	}
+
+	user := &models.User{}
+	in.Apply(user)
+
+	verrs, err := v.usersService.Insert(c, user)
+	if err != nil {
+		return err
+	}
+	if verrs.HasAny() {
+		return models.ValidationError(verrs)
+	}
+
+	c.Response().Header().Set("Location", "/api/v1/users/"+user.ID.String())
+	setETag(c, user.Version)
+	return c.Render(http.StatusCreated, r.JSON(dto.NewUser(*user)))
+}
+
+// Update replaces a user. This function is mapped to the path
+// PUT /api/v1/users/{user_id}
+func (v APIUsersResource) Update(c buffalo.Context) error {
+	user, err := v.usersService.Edit(c)
+	if err != nil {
+		return err
+	}
+
+	in := dto.UserInput{}
+	if err := apiBind(c, &in); err != nil {
+		return err
+	}
+	in.Apply(user)
+
+	verrs, err := v.usersService.Save(c, user)
+	if err != nil {
+		return err
+	}
+	if verrs.HasAny() {
+		return models.ValidationError(verrs)
+	}
+
+	setETag(c, user.Version)
+	return c.Render(http.StatusOK, r.JSON(dto.NewUser(*user)))
+}
+
+// Patch changes only the fields of a user that the body sends, as a JSON
+// Merge Patch or, with the application/json-patch+json type, a JSON Patch
+// of the UserInput keys. This function is mapped to the path
+// PATCH /api/v1/users/{user_id}
+func (v APIUsersResource) Patch(c buffalo.Context) error {
+	user, err := v.usersService.Edit(c)
+	if err != nil {
+		return err
+	}
+
+	doc, err := json.Marshal(dto.NewUserInput(*user))
+	if err != nil {
+		return err
+	}
+	in := dto.UserInput{}
+	if err := repository.ApplyPatch(c, doc, &in); err != nil {
+		return err
+	}
+	in.Apply(user)
+
+	verrs, err := v.usersService.Save(c, user)
+	if err != nil {
+		return err
+	}
+	if verrs.HasAny() {
+		return models.ValidationError(verrs)
+	}
+
+	setETag(c, user.Version)
+	return c.Render(http.StatusOK, r.JSON(dto.NewUser(*user)))
+}
+
+// Destroy deletes a user. This function is mapped to the path
+// DELETE /api/v1/users/{user_id}
+func (v APIUsersResource) Destroy(c buffalo.Context) error {
+	if _, err := v.usersService.Destroy(c); err != nil {
+		return err
+	}
+	return c.Render(http.StatusNoContent, nil)
+}
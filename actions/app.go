@@ -1,9 +1,16 @@
 package actions
 
 import (
+	"fmt"
+	"location_service_v1/ls_v2/events"
+	"location_service_v1/ls_v2/gql"
 	"location_service_v1/ls_v2/models"
+	"location_service_v1/ls_v2/ratelimit"
 	"location_service_v1/ls_v2/repository"
+	"location_service_v1/ls_v2/rpc"
 	"location_service_v1/ls_v2/service"
+	"location_service_v1/ls_v2/webhooks"
+	"time"
 
 	"github.com/gobuffalo/buffalo"
 	"github.com/gobuffalo/buffalo-pop/pop/popmw"
@@ -14,12 +21,16 @@ import (
 	paramlogger "github.com/gobuffalo/mw-paramlogger"
 	"github.com/gobuffalo/packr/v2"
 	"github.com/unrolled/secure"
+	"google.golang.org/grpc"
 )
 
 // ENV is used to help switch settings based on where the
 // application is being run. Default is "development".
 var ENV = envy.Get("GO_ENV", "development")
 var app *buffalo.App
+var grpcServer *grpc.Server
+var webhookWorker *webhooks.Worker
+var liveEvents *events.Broker
 var T *i18n.Translator
 
 // App is where all routes and middleware for buffalo
@@ -48,6 +59,23 @@ func App() *buffalo.App {
 		// Log request parameters (filters apply).
 		app.Use(paramlogger.ParameterLogger)
 
+		// Answer the errors of the handlers with the mapped status and, for
+		// JSON and XML, a uniform error body.
+		app.Use(errorResponses)
+
+		// Limit the requests of each address, before they take a
+		// transaction. The API groups limit each token instead, once
+		// apiAuthenticate has verified it, and each address only on its
+		// failed authentications.
+		limiter := rateLimiter()
+		app.Use(limiter.Middleware)
+
+		// Replay the response of a POST sent again with the same
+		// Idempotency-Key, once its transaction has committed. The keys
+		// of the clients without a token belong to the address the
+		// limiter sees, so RATE_LIMIT_PROXY_HEADER applies to them too.
+		app.Use(idempotent(idempotencyKeysService(), limiter.Address, idempotentRoutes...))
+
 		// Protect against CSRF attacks. https://www.owasp.org/index.php/Cross-Site_Request_Forgery_(CSRF)
 		// Remove to disable this.
 		app.Use(csrf.New)
@@ -60,29 +88,111 @@ func App() *buffalo.App {
 		// Setup and use translations:
 		app.Use(translations())
 
-		app.GET("/", HomeHandler)
+		// Let links choose the response format with "format=csv" etc.
+		app.Use(acceptFormat)
 
 		/* для простоты нужно называть контроллер "Название + Resource"
 		* потому что в движке идёт удаление постфикса Resource и оставляется только название контроллера
 		* предоположительно путь надо править как: points_controller при названии PointsController
 		 */
 
+		statsRepository := repository.NewStatsRepository()
+		statsService := service.NewStatsService(statsRepository)
+
+		HomeResource := NewHomeResource(statsService)
+		app.GET("/", HomeResource.HomeHandler)
+
+		// the changes of points and companies fire the webhooks
+		webhooksRepository := repository.NewWebhooksRepository()
+		webhooksService := service.NewWebhooksService(webhooksRepository)
+
 		companiesRepository := repository.NewCompaniesRepository()
-		companiesService := service.NewCompaniesService(companiesRepository)
-		CompaniesResource := NewCompanyResource(companiesService)
-		app.Resource("/companies", CompaniesResource)
+		companiesService := service.NewCompaniesService(companiesRepository, webhooksRepository)
+
+		// the changes of points also fire the live events of /events
+		eventsRepository := repository.NewEventsRepository()
 
 		pointsRepository := repository.NewPointsRepository()
-		pointsService := service.NewPointsService(pointsRepository)
+		pointsService := service.NewPointsService(pointsRepository, webhooksRepository, eventsRepository)
+
+		CompaniesResource := NewCompanyResource(companiesService, pointsService)
+		app.Resource("/companies", CompaniesResource)
+		app.PATCH("/companies/{company_id}", CompaniesResource.Patch)
+		app.GET("/companies/{company_id}/points", CompaniesResource.ListPoints)
+		app.POST("/companies/{company_id}/points", CompaniesResource.CreatePoint)
+
 		PointsResource := NewPointResource(pointsService, companiesService)
+		// declared before the resource so that "search" is not taken for a point_id
+		app.GET("/points/search", PointsResource.Search)
+		app.GET("/points/changes", PointsResource.Changes)
 		app.Resource("/points", PointsResource)
+		app.PATCH("/points/{point_id}", PointsResource.Patch)
 
 		app.GET("/pickpointlist", PointsResource.GetPickPointsList)
+		app.GET("/autocomplete", PointsResource.Autocomplete)
+
+		// A stream lasts as long as the client stays, too long to hold a
+		// transaction and its connection.
+		liveEvents = events.NewBroker(models.DB.URL(), app.Logger)
+		eventsHandler := Events(liveEvents)
+		app.Middleware.Skip(popmw.Transaction(models.DB), eventsHandler)
+		app.GET("/events", eventsHandler)
+
+		StatsResource := NewStatsResource(statsService)
+		app.GET("/stats/points", StatsResource.Points)
 
 		usersRepository := repository.NewUsersRepository()
 		usersService := service.NewUsersService(usersRepository)
 		UsersResource := NewUserResource(usersService)
 		app.Resource("/users", UsersResource)
+		app.PATCH("/users/{user_id}", UsersResource.Patch)
+
+		WebhooksResource := NewWebhookResource(webhooksService)
+		app.Resource("/webhooks", WebhooksResource)
+		app.POST("/webhooks/{webhook_id}/deliveries/{delivery_id}/resend", WebhooksResource.Resend)
+
+		app.GET("/api/openapi.json", OpenAPI)
+		app.GET("/api/docs", APIDocs)
+
+		// The JSON API authenticates with bearer tokens instead of the
+		// session cookie, so it needs no CSRF protection. It always
+		// answers JSON, errors included.
+		apiTokensRepository := repository.NewAPITokensRepository()
+		apiTokensService := service.NewAPITokensService(apiTokensRepository)
+
+		api := app.Group("/api/v1")
+		api.Middleware.Replace(csrf.New, apiJSON)
+		api.Middleware.Replace(limiter.Middleware, limiter.AuthFailures)
+		api.Use(apiAuthenticate(apiTokensService), limiter.Middleware)
+		APIPointsResource := NewAPIPointsResource(pointsService)
+		// declared before the resource so that "changes" is not taken for a point_id
+		api.GET("/points/changes", APIPointsResource.Changes)
+		api.POST("/points/bulk", APIPointsResource.Bulk)
+		api.Resource("/points", APIPointsResource)
+		api.PATCH("/points/{point_id}", APIPointsResource.Patch)
+		APICompaniesResource := NewAPICompaniesResource(companiesService)
+		api.Resource("/companies", APICompaniesResource)
+		api.PATCH("/companies/{company_id}", APICompaniesResource.Patch)
+		APIUsersResource := NewAPIUsersResource(usersService)
+		api.Resource("/users", APIUsersResource)
+		api.PATCH("/users/{user_id}", APIUsersResource.Patch)
+
+		// GraphQL shares the authentication of the JSON API.
+		schema, err := gql.NewSchema(pointsService, companiesService, usersService)
+		if err != nil {
+			app.Stop(err)
+		}
+		graphQL := app.Group("/graphql")
+		graphQL.Middleware.Replace(csrf.New, apiJSON)
+		graphQL.Middleware.Replace(limiter.Middleware, limiter.AuthFailures)
+		graphQL.Use(apiAuthenticate(apiTokensService), limiter.Middleware)
+		graphQL.POST("/", GraphQL(schema))
+
+		// The gRPC PointService is served by main on a port of its own.
+		grpcServer = rpc.NewGRPCServer(rpc.NewServer(models.DB, app.Logger, pointsService, apiTokensService))
+
+		// The webhook deliveries are sent by main in the background.
+		webhookWorker = webhooks.NewWorker(models.DB, app.Logger)
 
 		app.ServeFiles("/", assetsBox) // serve files from the public directory
 	}
@@ -90,6 +200,24 @@ func App() *buffalo.App {
 	return app
 }
 
+// GRPCServer is the gRPC PointService, sharing the services of the app.
+// App must be called first.
+func GRPCServer() *grpc.Server {
+	return grpcServer
+}
+
+// WebhookWorker sends the webhook deliveries queued by the app. App must
+// be called first.
+func WebhookWorker() *webhooks.Worker {
+	return webhookWorker
+}
+
+// LiveEvents is the broker of the /events streams. App must be called
+// first.
+func LiveEvents() *events.Broker {
+	return liveEvents
+}
+
 // translations will load locale files, set up the translator `actions.T`,
 // and will return a middleware to use to load the correct locale for each
 // request.
@@ -102,6 +230,37 @@ func translations() buffalo.MiddlewareFunc {
 	return T.Middleware()
 }
 
+// defaultRateLimits are the rate limits unless RATE_LIMITS sets others, in
+// the format of ratelimit.ParseRules. The deep pages of the point lists
+// are the most expensive requests. RATE_LIMITS="" turns limiting off.
+const defaultRateLimits = "GET /points=60/m, GET /api/v1/points=60/m, GET /points/changes=60/m, GET /api/v1/points/changes=60/m, /api/v1/*=300/m, /graphql=120/m"
+
+// rateLimiter limits the clients with buckets in memory, so each instance
+// of the app counts on its own. RATE_LIMIT_PROXY_HEADER names the header
+// of the client address behind a proxy, e.g. X-Forwarded-For.
+func rateLimiter() *ratelimit.Limiter {
+	rules, err := ratelimit.ParseRules(envy.Get("RATE_LIMITS", defaultRateLimits))
+	if err != nil {
+		app.Stop(err)
+	}
+	limiter := ratelimit.New(ratelimit.NewMemoryStore(), rules...)
+	limiter.ProxyHeader = envy.Get("RATE_LIMIT_PROXY_HEADER", "")
+	return limiter
+}
+
+// idempotencyKeysService keeps the Idempotency-Keys and their responses
+// for IDEMPOTENCY_TTL, a duration such as "24h".
+func idempotencyKeysService() *service.IdempotencyKeysService {
+	ttl, err := time.ParseDuration(envy.Get("IDEMPOTENCY_TTL", "24h"))
+	if err == nil && ttl <= 0 {
+		err = fmt.Errorf("IDEMPOTENCY_TTL must be positive, got %v", ttl)
+	}
+	if err != nil {
+		app.Stop(err)
+	}
+	return service.NewIdempotencyKeysService(repository.NewIdempotencyKeysRepository(), ttl)
+}
+
 // forceSSL will return a middleware that will redirect an incoming request
 // if it is not HTTPS. "http://example.com" => "https://example.com".
 // This middleware does **not** enable SSL. for your application. To do that
@@ -1,11 +1,12 @@
 package actions
 
 import (
-	"fmt"
 	"github.com/gobuffalo/buffalo"
 	"github.com/gobuffalo/x/responder"
+	"location_service_v1/ls_v2/models"
 	"location_service_v1/ls_v2/service"
 	"net/http"
+	"strconv"
 )
 
 // This file is generated by Buffalo. It offers a basic structure for
@@ -24,11 +25,13 @@ import (
 type CompaniesResource struct {
 	buffalo.Resource
 	companiesService *service.CompaniesService
+	pointsService    *service.PointsService
 }
 
-func NewCompanyResource(service *service.CompaniesService) *CompaniesResource {
+func NewCompanyResource(companiesService *service.CompaniesService, pointsService *service.PointsService) *CompaniesResource {
 	return &CompaniesResource{
-		companiesService: service,
+		companiesService: companiesService,
+		pointsService:    pointsService,
 	}
 }
 
@@ -36,6 +39,10 @@ func NewCompanyResource(service *service.CompaniesService) *CompaniesResource {
 // GET /companies
 func (v CompaniesResource) List(c buffalo.Context) error {
 
+	if wantsCursor(c) {
+		return v.scroll(c)
+	}
+
 	companies, q, err := v.companiesService.List(c)
 	if err != nil {
 		return err
@@ -48,9 +55,27 @@ func (v CompaniesResource) List(c buffalo.Context) error {
 		c.Set("companies", companies)
 		return c.Render(http.StatusOK, r.HTML("/companies/index.plush.html"))
 	}).Wants("json", func(c buffalo.Context) error {
-		return c.Render(200, r.JSON(companies))
+		return c.Render(200, r.JSON(pageList(c, sparse(c, companies), q.Paginator)))
 	}).Wants("xml", func(c buffalo.Context) error {
-		return c.Render(200, r.XML(companies))
+		return c.Render(200, r.XML(pageList(c, sparse(c, companies), q.Paginator)))
+	}).Respond(c)
+}
+
+// scroll renders a keyset paginated page of companies for JSON and XML
+// clients that use "after", "before" or "limit"
+func (v CompaniesResource) scroll(c buffalo.Context) error {
+
+	companies, page, err := v.companiesService.Scroll(c)
+	if err != nil {
+		return err
+	}
+
+	list := cursorList(c, sparse(c, companies), page)
+
+	return responder.Wants("json", func(c buffalo.Context) error {
+		return c.Render(200, r.JSON(list))
+	}).Wants("xml", func(c buffalo.Context) error {
+		return c.Render(200, r.XML(list))
 	}).Respond(c)
 }
 
@@ -63,15 +88,26 @@ func (v CompaniesResource) Show(c buffalo.Context) error {
 	if err != nil {
 		return err
 	}
+	if notModified(c, company.Version) {
+		return c.Render(http.StatusNotModified, nil)
+	}
 
 	return responder.Wants("html", func(c buffalo.Context) error {
+		// the company_id of the path scopes the points to this company
+		points, q, err := v.pointsService.List(c)
+		if err != nil {
+			return err
+		}
+
 		c.Set("company", company)
+		c.Set("points", points)
+		c.Set("pagination", q.Paginator)
 
 		return c.Render(http.StatusOK, r.HTML("/companies/show.plush.html"))
 	}).Wants("json", func(c buffalo.Context) error {
-		return c.Render(200, r.JSON(company))
+		return c.Render(200, r.JSON(sparse(c, company)))
 	}).Wants("xml", func(c buffalo.Context) error {
-		return c.Render(200, r.XML(company))
+		return c.Render(200, r.XML(sparse(c, company)))
 	}).Respond(c)
 }
 
@@ -79,10 +115,6 @@ func (v CompaniesResource) Show(c buffalo.Context) error {
 // This function is mapped to the path GET /companies/new
 func (v CompaniesResource) New(c buffalo.Context) error {
 	company := v.companiesService.New(c)
-	if company == nil {
-		return fmt.Errorf("somthing goes worng")
-	}
-
 	c.Set("company", company)
 	return c.Render(http.StatusOK, r.HTML("/companies/new.plush.html"))
 }
@@ -107,9 +139,9 @@ func (v CompaniesResource) Create(c buffalo.Context) error {
 
 			return c.Render(http.StatusUnprocessableEntity, r.HTML("/companies/new.plush.html"))
 		}).Wants("json", func(c buffalo.Context) error {
-			return c.Render(http.StatusUnprocessableEntity, r.JSON(verrs))
+			return models.ValidationError(verrs)
 		}).Wants("xml", func(c buffalo.Context) error {
-			return c.Render(http.StatusUnprocessableEntity, r.XML(verrs))
+			return models.ValidationError(verrs)
 		}).Respond(c)
 	}
 
@@ -120,8 +152,10 @@ func (v CompaniesResource) Create(c buffalo.Context) error {
 		// and redirect to the show page
 		return c.Redirect(http.StatusSeeOther, "/companies/%v", company.ID)
 	}).Wants("json", func(c buffalo.Context) error {
+		setETag(c, company.Version)
 		return c.Render(http.StatusCreated, r.JSON(company))
 	}).Wants("xml", func(c buffalo.Context) error {
+		setETag(c, company.Version)
 		return c.Render(http.StatusCreated, r.XML(company))
 	}).Respond(c)
 }
@@ -144,6 +178,9 @@ func (v CompaniesResource) Edit(c buffalo.Context) error {
 func (v CompaniesResource) Update(c buffalo.Context) error {
 
 	verrs, company, err := v.companiesService.Update(c)
+	if isConflict(err) && requestFormat(c) == "html" {
+		return v.conflict(c, company)
+	}
 	if err != nil {
 		return err
 	}
@@ -159,9 +196,9 @@ func (v CompaniesResource) Update(c buffalo.Context) error {
 
 			return c.Render(http.StatusUnprocessableEntity, r.HTML("/companies/edit.plush.html"))
 		}).Wants("json", func(c buffalo.Context) error {
-			return c.Render(http.StatusUnprocessableEntity, r.JSON(verrs))
+			return models.ValidationError(verrs)
 		}).Wants("xml", func(c buffalo.Context) error {
-			return c.Render(http.StatusUnprocessableEntity, r.XML(verrs))
+			return models.ValidationError(verrs)
 		}).Respond(c)
 	}
 
@@ -172,8 +209,46 @@ func (v CompaniesResource) Update(c buffalo.Context) error {
 		// and redirect to the show page
 		return c.Redirect(http.StatusSeeOther, "/companies/%v", company.ID)
 	}).Wants("json", func(c buffalo.Context) error {
+		setETag(c, company.Version)
 		return c.Render(http.StatusOK, r.JSON(company))
 	}).Wants("xml", func(c buffalo.Context) error {
+		setETag(c, company.Version)
+		return c.Render(http.StatusOK, r.XML(company))
+	}).Respond(c)
+}
+
+// conflict renders the edit form again when the Company was changed by
+// someone else since the form was opened. The page shows the saved values
+// next to the user's, which are saved over the new version when sent again.
+func (v CompaniesResource) conflict(c buffalo.Context, current *models.Company) error {
+	mine := *current
+	if err := c.Bind(&mine); err != nil {
+		return err
+	}
+
+	c.Set("current", current)
+	c.Set("company", &mine)
+	return c.Render(http.StatusConflict, r.HTML("/companies/conflict.plush.html"))
+}
+
+// Patch changes only the fields of a Company that the body sends, as a
+// JSON Merge Patch or, with the application/json-patch+json type, a JSON
+// Patch. This function is mapped to the path PATCH /companies/{company_id}
+func (v CompaniesResource) Patch(c buffalo.Context) error {
+
+	verrs, company, err := v.companiesService.Patch(c)
+	if err != nil {
+		return err
+	}
+	if verrs.HasAny() {
+		return models.ValidationError(verrs)
+	}
+
+	return responder.Wants("json", func(c buffalo.Context) error {
+		setETag(c, company.Version)
+		return c.Render(http.StatusOK, r.JSON(company))
+	}).Wants("xml", func(c buffalo.Context) error {
+		setETag(c, company.Version)
 		return c.Render(http.StatusOK, r.XML(company))
 	}).Respond(c)
 }
@@ -182,14 +257,22 @@ func (v CompaniesResource) Update(c buffalo.Context) error {
 // to the path DELETE /companies/{company_id}
 func (v CompaniesResource) Destroy(c buffalo.Context) error {
 
+	// The points are kept but lose their company
+	affected, err := v.companiesService.CountPoints(c)
+	if err != nil {
+		return err
+	}
+
 	company, err := v.companiesService.Destroy(c)
 	if err != nil {
 		return err
 	}
 
+	c.Response().Header().Set("X-Affected-Points", strconv.Itoa(affected))
+
 	return responder.Wants("html", func(c buffalo.Context) error {
 		// If there are no errors set a flash message
-		c.Flash().Add("success", T.Translate(c, "company.destroyed.success"))
+		c.Flash().Add("success", T.Translate(c, "company.destroyed.success", map[string]interface{}{"Points": affected}))
 
 		// Redirect to the index page
 		return c.Redirect(http.StatusSeeOther, "/companies")
@@ -1,5 +1,11 @@
 package actions
 
+import (
+	"location_service_v1/ls_v2/models"
+
+	"github.com/gofrs/uuid"
+)
+
 func (as *ActionSuite) Test_CompaniesResource_List() {
 	as.Fail("Not Implemented!")
 }
@@ -17,7 +23,20 @@ func (as *ActionSuite) Test_CompaniesResource_Update() {
 }
 
 func (as *ActionSuite) Test_CompaniesResource_Destroy() {
-	as.Fail("Not Implemented!")
+	company := &models.Company{Name: "pickpoint"}
+	as.NoError(as.DB.Create(company))
+	point := &models.Point{Name: "Tverskaya 7", CompanyID: company.ID}
+	as.NoError(as.DB.Create(point))
+
+	res := as.JSON("/companies/%s", company.ID).Delete()
+	as.Equal(200, res.Code)
+	as.Equal("1", res.Header().Get("X-Affected-Points"))
+
+	// the point is kept without a company
+	kept := &models.Point{}
+	as.NoError(as.DB.Find(kept, point.ID))
+	as.Equal(uuid.Nil, kept.CompanyID)
+	as.Equal(point.Version+1, kept.Version)
 }
 
 func (as *ActionSuite) Test_CompaniesResource_New() {
@@ -27,3 +46,14 @@ func (as *ActionSuite) Test_CompaniesResource_New() {
 func (as *ActionSuite) Test_CompaniesResource_Edit() {
 	as.Fail("Not Implemented!")
 }
+
+func (as *ActionSuite) Test_CompaniesResource_ListPoints() {
+	company := &models.Company{Name: "pickpoint"}
+	as.NoError(as.DB.Create(company))
+	as.NoError(as.DB.Create(&models.Point{Name: "Tverskaya 7", CompanyID: company.ID}))
+	as.NoError(as.DB.Create(&models.Point{Name: "Elsewhere"}))
+
+	res := as.JSON("/companies/%s/points", company.ID).Get()
+	as.Equal(200, res.Code)
+	as.Equal("1", res.Header().Get("X-Total-Count"))
+}
@@ -0,0 +1,98 @@
+package actions
+
+import (
+	"location_service_v1/ls_v2/models"
+	"net/http"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/x/responder"
+)
+
+// ListPoints gets the Points of one Company. This function is mapped to
+// the path GET /companies/{company_id}/points
+func (v CompaniesResource) ListPoints(c buffalo.Context) error {
+
+	company, err := v.companiesService.Find(c)
+	if err != nil {
+		return err
+	}
+
+	if wantsCursor(c) {
+		points, page, err := v.pointsService.Scroll(c)
+		if err != nil {
+			return err
+		}
+		list := cursorList(c, sparse(c, points), page)
+
+		return responder.Wants("json", func(c buffalo.Context) error {
+			return c.Render(200, r.JSON(list))
+		}).Wants("xml", func(c buffalo.Context) error {
+			return c.Render(200, r.XML(list))
+		}).Respond(c)
+	}
+
+	// the company_id of the path scopes the points to this company
+	points, q, err := v.pointsService.List(c)
+	if err != nil {
+		return err
+	}
+
+	return responder.Wants("html", func(c buffalo.Context) error {
+		// the company page already has a paginated table of its points
+		return c.Redirect(http.StatusSeeOther, "/companies/%v", company.ID)
+	}).Wants("json", func(c buffalo.Context) error {
+		return c.Render(200, r.JSON(pageList(c, sparse(c, points), q.Paginator)))
+	}).Wants("xml", func(c buffalo.Context) error {
+		return c.Render(200, r.XML(pageList(c, sparse(c, points), q.Paginator)))
+	}).Respond(c)
+}
+
+// CreatePoint adds a Point to one Company. This function is mapped to
+// the path POST /companies/{company_id}/points
+func (v CompaniesResource) CreatePoint(c buffalo.Context) error {
+
+	company, err := v.companiesService.Find(c)
+	if err != nil {
+		return err
+	}
+
+	verrs, point, err := v.pointsService.CreateForCompany(c, company.ID)
+	if err != nil {
+		return err
+	}
+
+	if verrs.HasAny() {
+		return responder.Wants("html", func(c buffalo.Context) error {
+			companies, err := v.companiesService.All(c)
+			if err != nil {
+				return err
+			}
+
+			// Make the errors available inside the html template
+			c.Set("errors", verrs)
+
+			// Render again the new.html template that the user can
+			// correct the input.
+			c.Set("point", point)
+			c.Set("companies", companies)
+
+			return c.Render(http.StatusUnprocessableEntity, r.HTML("/points/new.plush.html"))
+		}).Wants("json", func(c buffalo.Context) error {
+			return models.ValidationError(verrs)
+		}).Wants("xml", func(c buffalo.Context) error {
+			return models.ValidationError(verrs)
+		}).Respond(c)
+	}
+
+	return responder.Wants("html", func(c buffalo.Context) error {
+		// If there are no errors set a success message
+		c.Flash().Add("success", T.Translate(c, "point.created.success"))
+
+		// and redirect to the show page of the company
+		return c.Redirect(http.StatusSeeOther, "/companies/%v", company.ID)
+	}).Wants("json", func(c buffalo.Context) error {
+		return c.Render(http.StatusCreated, r.JSON(point))
+	}).Wants("xml", func(c buffalo.Context) error {
+		return c.Render(http.StatusCreated, r.XML(point))
+	}).Respond(c)
+}
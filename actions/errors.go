@@ -0,0 +1,93 @@
+package actions
+
+import (
+	"errors"
+	"location_service_v1/ls_v2/dto"
+	"location_service_v1/ls_v2/models"
+	"net/http"
+
+	"github.com/gobuffalo/buffalo"
+)
+
+// internalErrorCode is the code of the errors that are not models.Error.
+// Their details are logged but not shown.
+const internalErrorCode = "internal_error"
+
+// errorStatuses are the response statuses of the domain error codes.
+var errorStatuses = map[models.ErrorCode]int{
+	models.CodeBadRequest:         http.StatusBadRequest,
+	models.CodeUnauthorized:       http.StatusUnauthorized,
+	models.CodeNotFound:           http.StatusNotFound,
+	models.CodeConflict:           http.StatusConflict,
+	models.CodeInvalid:            http.StatusUnprocessableEntity,
+	models.CodeUpstream:           http.StatusBadGateway,
+	models.CodePreconditionFailed: http.StatusPreconditionFailed,
+	models.CodeRateLimited:        http.StatusTooManyRequests,
+	models.CodeKeyReused:          http.StatusUnprocessableEntity,
+}
+
+// statusCodes are the codes of the statuses Buffalo and its middleware
+// answer with c.Error, e.g. 403 from the CSRF check.
+var statusCodes = map[int]string{
+	http.StatusBadRequest:          string(models.CodeBadRequest),
+	http.StatusUnauthorized:        string(models.CodeUnauthorized),
+	http.StatusForbidden:           "forbidden",
+	http.StatusNotFound:            string(models.CodeNotFound),
+	http.StatusConflict:            string(models.CodeConflict),
+	http.StatusPreconditionFailed:  string(models.CodePreconditionFailed),
+	http.StatusUnprocessableEntity: string(models.CodeInvalid),
+	http.StatusTooManyRequests:     string(models.CodeRateLimited),
+}
+
+// errorResponses answers the errors returned by the handlers. JSON and XML
+// clients get a dto.Error body, HTML pages keep the error pages of Buffalo
+// with the mapped status. It runs outside of the transaction middleware, so
+// the transaction has already been rolled back.
+func errorResponses(next buffalo.Handler) buffalo.Handler {
+	return func(c buffalo.Context) error {
+		err := next(c)
+		if err == nil {
+			return nil
+		}
+
+		body := newErrorBody(err)
+		status := body.Error.Status
+		if status >= http.StatusInternalServerError {
+			c.Logger().Error(err)
+		}
+
+		switch requestFormat(c) {
+		case "json":
+			return c.Render(status, r.JSON(body))
+		case "xml":
+			return c.Render(status, r.XML(body))
+		}
+		return c.Error(status, err)
+	}
+}
+
+// newErrorBody describes err for the client.
+func newErrorBody(err error) dto.Error {
+	var e *models.Error
+	if errors.As(err, &e) {
+		status, ok := errorStatuses[e.Code]
+		if !ok {
+			status = http.StatusInternalServerError
+		}
+		body := dto.NewError(status, string(e.Code), e.Message)
+		body.Error.Fields = e.Fields
+		return body
+	}
+
+	var herr buffalo.HTTPError
+	if errors.As(err, &herr) && herr.Status < http.StatusInternalServerError {
+		code, ok := statusCodes[herr.Status]
+		if !ok {
+			code = string(models.CodeBadRequest)
+		}
+		return dto.NewError(herr.Status, code, herr.Cause.Error())
+	}
+
+	status := http.StatusInternalServerError
+	return dto.NewError(status, internalErrorCode, http.StatusText(status))
+}
@@ -0,0 +1,30 @@
+package actions
+
+import (
+	"location_service_v1/ls_v2/dto"
+
+	"github.com/gofrs/uuid"
+)
+
+func (as *ActionSuite) Test_ErrorResponses_NotFound() {
+	res := as.JSON("/points/%s", uuid.Must(uuid.NewV4())).Get()
+	as.Equal(404, res.Code)
+
+	body := dto.Error{}
+	res.Bind(&body)
+	as.Equal("not_found", body.Error.Code)
+	as.Equal("point not found", body.Error.Message)
+
+	xml := as.XML("/points/%s", uuid.Must(uuid.NewV4())).Get()
+	as.Equal(404, xml.Code)
+	as.Contains(xml.Body.String(), "<code>not_found</code>")
+}
+
+func (as *ActionSuite) Test_ErrorResponses_BadRequest() {
+	res := as.JSON("/points?sort=password").Get()
+	as.Equal(400, res.Code)
+
+	body := dto.Error{}
+	res.Bind(&body)
+	as.Equal("bad_request", body.Error.Code)
+}
@@ -0,0 +1,34 @@
+package actions
+
+import (
+	"errors"
+	"location_service_v1/ls_v2/models"
+
+	"github.com/gobuffalo/buffalo"
+)
+
+// setETag sets the ETag of a response with one record, for the If-Match
+// header of its next update.
+func setETag(c buffalo.Context, version int) {
+	c.Response().Header().Set("ETag", models.ETag(version))
+}
+
+// notModified sets the ETag of a record and reports whether the
+// If-None-Match header of the request names it already, in which case the
+// handler answers 304 Not Modified without a body. HTML pages are always
+// rendered, as they carry flash messages and a fresh CSRF token.
+func notModified(c buffalo.Context, version int) bool {
+	if requestFormat(c) == "html" {
+		return false
+	}
+	setETag(c, version)
+	match := c.Request().Header.Get("If-None-Match")
+	return match != "" && models.MatchETag(match, models.ETag(version), true)
+}
+
+// isConflict reports whether err is a models.ConflictError, e.g. an edit
+// form sent for a version of the record that is no longer current.
+func isConflict(err error) bool {
+	var e *models.Error
+	return errors.As(err, &e) && e.Code == models.CodeConflict
+}
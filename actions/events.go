@@ -0,0 +1,63 @@
+package actions
+
+import (
+	"fmt"
+	"location_service_v1/ls_v2/events"
+	"location_service_v1/ls_v2/models"
+	"net/http"
+	"time"
+
+	"github.com/gobuffalo/buffalo"
+)
+
+// heartbeatInterval is how often an idle stream sends a comment, so that
+// proxies do not close it.
+const heartbeatInterval = 15 * time.Second
+
+// Events streams the live events as Server-Sent Events: point.created,
+// point.updated and point.deleted with the point, and import.progress
+// with the import run. "company_id" and "city" filter the point events.
+// This function is mapped to the path GET /events
+func Events(broker *events.Broker) buffalo.Handler {
+	return func(c buffalo.Context) error {
+		filter, err := events.ParseFilter(c.Param("company_id"), c.Param("city"))
+		if err != nil {
+			return models.BadRequestError(err)
+		}
+
+		res := c.Response()
+		flusher, ok := res.(http.Flusher)
+		if !ok {
+			return fmt.Errorf("the response cannot be streamed")
+		}
+
+		sub := broker.Subscribe(filter)
+		defer broker.Unsubscribe(sub)
+
+		res.Header().Set("Content-Type", "text/event-stream")
+		res.Header().Set("Cache-Control", "no-cache")
+		res.Header().Set("X-Accel-Buffering", "no")
+		res.WriteHeader(http.StatusOK)
+		fmt.Fprint(res, "retry: 3000\n\n")
+		flusher.Flush()
+
+		heartbeat := time.NewTicker(heartbeatInterval)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case <-c.Request().Context().Done():
+				return nil
+			case e, ok := <-sub.Events:
+				// closed for falling behind; the client reconnects
+				if !ok {
+					return nil
+				}
+				fmt.Fprintf(res, "event: %s\ndata: %s\n\n", e.Type, e.Data)
+			case <-heartbeat.C:
+				fmt.Fprint(res, ": ping\n\n")
+			}
+			flusher.Flush()
+		}
+	}
+}
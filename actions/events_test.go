@@ -0,0 +1,7 @@
+package actions
+
+func (as *ActionSuite) Test_Events_BadFilter() {
+	res := as.JSON("/events?company_id=42").Get()
+	as.Equal(400, res.Code)
+	as.Contains(res.Body.String(), "company_id must be a UUID")
+}
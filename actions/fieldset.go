@@ -0,0 +1,107 @@
+package actions
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"reflect"
+	"strings"
+
+	"github.com/gobuffalo/buffalo"
+)
+
+// record is a model reduced to the fields asked for by the "fields"
+// parameter, in the order they were asked for.
+type record struct {
+	name   string
+	fields []recordField
+}
+
+type recordField struct {
+	key   string
+	value interface{}
+}
+
+// MarshalJSON writes the record as an object with the requested keys.
+func (r record) MarshalJSON() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+	for i, f := range r.fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(f.key)
+		value, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// MarshalXML writes the record as an element with one child per key.
+func (r record) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	// at the top level encoding/xml names the element after the Go type
+	if start.Name.Local == "record" {
+		start.Name.Local = r.name
+	}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	for _, f := range r.fields {
+		if err := e.EncodeElement(f.value, xml.StartElement{Name: xml.Name{Local: f.key}}); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// sparse reduces a model, or a slice of models, to the json keys listed in
+// the "fields" parameter. The repositories have already rejected unknown
+// keys and read only the needed columns. Without the parameter the data is
+// returned as it is.
+func sparse(c buffalo.Context, data interface{}) interface{} {
+	param := strings.TrimSpace(c.Param("fields"))
+	if param == "" {
+		return data
+	}
+	keys := strings.Split(param, ",")
+	for i := range keys {
+		keys[i] = strings.TrimSpace(keys[i])
+	}
+
+	v := reflect.Indirect(reflect.ValueOf(data))
+	if v.Kind() != reflect.Slice {
+		return toRecord(v, keys)
+	}
+
+	records := make([]record, v.Len())
+	for i := range records {
+		records[i] = toRecord(reflect.Indirect(v.Index(i)), keys)
+	}
+	return records
+}
+
+// toRecord picks the fields of the struct v by their json keys.
+func toRecord(v reflect.Value, keys []string) record {
+	t := v.Type()
+	index := map[string]int{}
+	for i := 0; i < t.NumField(); i++ {
+		key := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if key != "" && key != "-" {
+			index[key] = i
+		}
+	}
+
+	r := record{name: t.Name()}
+	for _, key := range keys {
+		if i, ok := index[key]; ok {
+			r.fields = append(r.fields, recordField{key: key, value: v.Field(i).Interface()})
+		}
+	}
+	return r
+}
@@ -0,0 +1,44 @@
+package actions
+
+import (
+	"strings"
+
+	"github.com/gobuffalo/buffalo"
+)
+
+// formatTypes maps the "format" parameter to the content type it asks for.
+var formatTypes = map[string]string{
+	"html":    "text/html",
+	"json":    "application/json",
+	"xml":     "application/xml",
+	"csv":     "text/csv",
+	"geojson": "application/geo+json",
+	"kml":     "application/vnd.google-earth.kml+xml",
+}
+
+// acceptFormat lets plain links pick the response format with "format=csv"
+// and the like, by replacing the Accept header the responders look at.
+func acceptFormat(next buffalo.Handler) buffalo.Handler {
+	return func(c buffalo.Context) error {
+		if ct, ok := formatTypes[c.Param("format")]; ok {
+			c.Request().Header.Set("Accept", ct)
+		}
+		return next(c)
+	}
+}
+
+// requestFormat is "json" or "xml" when the Accept or Content-Type header
+// asks for it and "html" otherwise. Browsers list xml after html in their
+// Accept header, so html wins over xml.
+func requestFormat(c buffalo.Context) string {
+	ct := strings.ToLower(c.Request().Header.Get("Accept") + " " + c.Request().Header.Get("Content-Type"))
+	switch {
+	case strings.Contains(ct, "json"):
+		return "json"
+	case strings.Contains(ct, "html"):
+		return "html"
+	case strings.Contains(ct, "xml"):
+		return "xml"
+	}
+	return "html"
+}
@@ -0,0 +1,38 @@
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"location_service_v1/ls_v2/gql"
+	"location_service_v1/ls_v2/models"
+	"net/http"
+
+	"github.com/gobuffalo/buffalo"
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// graphQLRequest is the body of a POST /graphql request.
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// GraphQL runs the queries and mutations of the schema. Errors of the
+// resolvers are part of the response body, as GraphQL clients expect.
+// This function is mapped to the path POST /graphql
+func GraphQL(schema *graphql.Schema) buffalo.Handler {
+	return func(c buffalo.Context) error {
+		req := graphQLRequest{}
+		if err := json.NewDecoder(c.Request().Body).Decode(&req); err != nil {
+			return models.BadRequestError(fmt.Errorf("invalid body: %v", err))
+		}
+		if req.Query == "" {
+			return models.BadRequestError(fmt.Errorf("missing query"))
+		}
+
+		ctx := gql.WithContext(c.Request().Context(), c)
+		res := schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+		return c.Render(http.StatusOK, r.JSON(res))
+	}
+}
@@ -0,0 +1,63 @@
+package actions
+
+import (
+	"location_service_v1/ls_v2/models"
+)
+
+func (as *ActionSuite) Test_GraphQL() {
+	company := &models.Company{Name: "PickPoint"}
+	as.NoError(as.DB.Create(company))
+	for _, name := range []string{"Tverskaya 7", "Arbat 1"} {
+		as.NoError(as.DB.Create(&models.Point{Name: name, CityName: "Moscow", CompanyID: company.ID}))
+	}
+
+	req := as.JSON("/graphql")
+	as.Equal(401, req.Post(graphQLRequest{Query: "{ companies { nodes { name } } }"}).Code)
+
+	req.Headers["Authorization"] = "Bearer " + as.apiToken()
+	res := req.Post(graphQLRequest{Query: `{
+		companies(name: "Pick") { nodes { name points { name city company { name } } } }
+	}`})
+	as.Equal(200, res.Code)
+
+	body := struct {
+		Data struct {
+			Companies struct {
+				Nodes []struct {
+					Name   string
+					Points []struct {
+						Name    string
+						City    string
+						Company struct{ Name string }
+					}
+				}
+			}
+		}
+		Errors []interface{}
+	}{}
+	res.Bind(&body)
+	as.Empty(body.Errors)
+	as.Len(body.Data.Companies.Nodes, 1)
+	as.Len(body.Data.Companies.Nodes[0].Points, 2)
+	as.Equal("PickPoint", body.Data.Companies.Nodes[0].Points[0].Company.Name)
+}
+
+func (as *ActionSuite) Test_GraphQL_Errors() {
+	req := as.JSON("/graphql")
+	req.Headers["Authorization"] = "Bearer " + as.apiToken()
+
+	res := req.Post(graphQLRequest{Query: `mutation { createCompany(input: {name: ""}) { id } }`})
+	as.Equal(200, res.Code)
+
+	body := struct {
+		Errors []struct {
+			Message    string
+			Extensions map[string]interface{}
+		}
+	}{}
+	res.Bind(&body)
+	as.Len(body.Errors, 1)
+	as.Equal(string(models.CodeInvalid), body.Errors[0].Extensions["code"])
+
+	as.Equal(400, req.Post(graphQLRequest{}).Code)
+}
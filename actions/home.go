@@ -1,9 +1,38 @@
 package actions
 
-import "github.com/gobuffalo/buffalo"
+import (
+	"location_service_v1/ls_v2/service"
 
-// HomeHandler is a default handler to serve up
-// a home page.
-func HomeHandler(c buffalo.Context) error {
-	return c.Render(200, r.HTML("index.html"))
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/x/responder"
+)
+
+// HomeResource serves the home page
+type HomeResource struct {
+	statsService *service.StatsService
+}
+
+// NewHomeResource is a
+func NewHomeResource(statsService *service.StatsService) *HomeResource {
+	return &HomeResource{
+		statsService: statsService,
+	}
+}
+
+// HomeHandler shows the dashboard with the totals, the latest imports, the
+// recently created points and the data-quality problems, each linked to the
+// filtered list. This function is mapped to the path GET /
+func (v HomeResource) HomeHandler(c buffalo.Context) error {
+
+	dashboard, err := v.statsService.Dashboard(c)
+	if err != nil {
+		return err
+	}
+
+	return responder.Wants("html", func(c buffalo.Context) error {
+		c.Set("dashboard", dashboard)
+		return c.Render(200, r.HTML("index.html"))
+	}).Wants("json", func(c buffalo.Context) error {
+		return c.Render(200, r.JSON(dashboard))
+	}).Respond(c)
 }
@@ -1,8 +1,15 @@
 package actions
 
+import "location_service_v1/ls_v2/models"
+
 func (as *ActionSuite) Test_HomeHandler() {
+	as.NoError(as.DB.Create(&models.ImportRun{Source: "pickpoint", Status: models.ImportFailed, Error: "connection refused"}))
+
 	res := as.HTML("/").Get()
 
 	as.Equal(200, res.Code)
-	as.Contains(res.Body.String(), "Welcome to Buffalo")
+	body := res.Body.String()
+	as.Contains(body, "Dashboard")
+	as.Contains(body, "connection refused")
+	as.Contains(body, "problem=no_company")
 }
@@ -0,0 +1,186 @@
+package actions
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"location_service_v1/ls_v2/models"
+	"location_service_v1/ls_v2/service"
+	"net/http"
+	"strings"
+
+	"github.com/gobuffalo/buffalo"
+)
+
+// maxIdempotencyKey is the longest Idempotency-Key header accepted.
+const maxIdempotencyKey = 255
+
+// idempotentRoutes are the routes that replay their response to a request
+// sent again with the same Idempotency-Key.
+var idempotentRoutes = []string{
+	"POST /points",
+	"POST /companies",
+	"POST /api/v1/points",
+	"POST /api/v1/companies",
+}
+
+// replayedHeaders are the response headers stored with an Idempotency-Key.
+var replayedHeaders = []string{"Content-Type", "Location", "ETag"}
+
+// idempotent replays the stored response of the routes to a request that
+// repeats the Idempotency-Key of an earlier one, e.g. a retry after a
+// network failure, instead of running it again. A key sent with another
+// request is answered with 422. Only the responses under 400, whose
+// transaction has committed, are stored; the key of a failed request can
+// be retried. It runs outside of the transaction middleware so that the
+// response is stored once the changes are committed. address is the client
+// address of a request without a bearer token, as the rate limiter sees it.
+func idempotent(idempotencyKeysService *service.IdempotencyKeysService, address func(*http.Request) string, routes ...string) buffalo.MiddlewareFunc {
+	return func(next buffalo.Handler) buffalo.Handler {
+		return func(c buffalo.Context) error {
+			key := strings.TrimSpace(c.Request().Header.Get("Idempotency-Key"))
+			route := routeName(c)
+			res, ok := c.Response().(*buffalo.Response)
+			if key == "" || !ok || !containsRoute(routes, route) {
+				return next(c)
+			}
+			if len(key) > maxIdempotencyKey {
+				return models.BadRequestError(errIdempotencyKeyTooLong)
+			}
+
+			hash, err := requestHash(c.Request())
+			if err != nil {
+				return models.BadRequestError(err)
+			}
+
+			stored, started, err := idempotencyKeysService.Begin(requestClient(c.Request(), address), key, route, hash)
+			if err != nil {
+				return err
+			}
+			if !started {
+				return replay(c, stored, route, hash)
+			}
+
+			rec := &responseRecorder{ResponseWriter: res.ResponseWriter}
+			res.ResponseWriter = rec
+			err = next(c)
+			res.ResponseWriter = rec.ResponseWriter
+
+			if err != nil || res.Status == 0 || res.Status >= http.StatusBadRequest {
+				if rerr := idempotencyKeysService.Release(stored); rerr != nil {
+					c.Logger().Error(rerr)
+				}
+				return err
+			}
+
+			headers := map[string]string{}
+			for _, name := range replayedHeaders {
+				if v := res.Header().Get(name); v != "" {
+					headers[name] = v
+				}
+			}
+			b, _ := json.Marshal(headers)
+			stored.Status = res.Status
+			stored.Headers = string(b)
+			stored.Body = rec.body.String()
+			if ferr := idempotencyKeysService.Finish(stored); ferr != nil {
+				c.Logger().Error(ferr)
+			}
+			return nil
+		}
+	}
+}
+
+// errIdempotencyKeyTooLong is the error of an Idempotency-Key longer than
+// maxIdempotencyKey.
+var errIdempotencyKeyTooLong = errors.New("the Idempotency-Key is longer than 255 characters")
+
+// replay answers with the stored response of key, unless the request is
+// not the one the key was first sent with or that one is still running.
+func replay(c buffalo.Context, key *models.IdempotencyKey, route, hash string) error {
+	if !key.Matches(route, hash) {
+		return models.KeyReusedError()
+	}
+	if key.Running() {
+		return models.ConflictError("a request with the Idempotency-Key is still running")
+	}
+
+	headers := map[string]string{}
+	if err := json.Unmarshal([]byte(key.Headers), &headers); err != nil {
+		return err
+	}
+	h := c.Response().Header()
+	for name, v := range headers {
+		h.Set(name, v)
+	}
+	h.Set("Idempotent-Replayed", "true")
+	c.Response().WriteHeader(key.Status)
+	_, err := c.Response().Write([]byte(key.Body))
+	return err
+}
+
+// routeName is the method and route pattern of the request, e.g.
+// "POST /api/v1/points".
+func routeName(c buffalo.Context) string {
+	route, ok := c.Value("current_route").(buffalo.RouteInfo)
+	if !ok {
+		return ""
+	}
+	path := route.Path
+	if path != "/" {
+		path = strings.TrimSuffix(path, "/")
+	}
+	return route.Method + " " + path
+}
+
+// containsRoute reports whether route is one of routes.
+func containsRoute(routes []string, route string) bool {
+	for _, r := range routes {
+		if r == route {
+			return true
+		}
+	}
+	return false
+}
+
+// requestHash identifies the request r: its method, URL, content type and
+// body. The body is put back for the handler. Buffalo has already parsed
+// the form bodies, so their values are hashed instead.
+func requestHash(r *http.Request) (string, error) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return "", err
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	h := sha256.New()
+	for _, part := range []string{r.Method, r.URL.RequestURI(), r.Header.Get("Content-Type"), r.PostForm.Encode()} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// requestClient is the owner of the Idempotency-Keys of r: its bearer
+// token, hashed so that the secret is not kept, or else its address.
+func requestClient(r *http.Request, address func(*http.Request) string) string {
+	if secret := bearerToken(r.Header.Get("Authorization")); secret != "" {
+		return "token:" + models.HashToken(secret)
+	}
+	return address(r)
+}
+
+// responseRecorder copies the body written to a response.
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
@@ -0,0 +1,74 @@
+package actions
+
+import (
+	"location_service_v1/ls_v2/dto"
+	"location_service_v1/ls_v2/models"
+	"location_service_v1/ls_v2/ratelimit"
+	"net/http/httptest"
+	"testing"
+)
+
+func (as *ActionSuite) Test_Idempotency_Replay() {
+	req := as.JSON("/api/v1/points")
+	req.Headers["Authorization"] = "Bearer " + as.apiToken()
+	req.Headers["Idempotency-Key"] = "3f1c2a4e-create-tverskaya"
+
+	input := dto.PointInput{Name: "Tverskaya 7", City: "Moscow", OwnerID: 5}
+	res := req.Post(input)
+	as.Equal(201, res.Code)
+	as.Empty(res.Header().Get("Idempotent-Replayed"))
+	first := dto.Point{}
+	res.Bind(&first)
+
+	res = req.Post(input)
+	as.Equal(201, res.Code)
+	as.Equal("true", res.Header().Get("Idempotent-Replayed"))
+	as.NotEmpty(res.Header().Get("ETag"))
+	replayed := dto.Point{}
+	res.Bind(&replayed)
+	as.Equal(first.ID, replayed.ID)
+
+	count, err := as.DB.Where("name = ?", "Tverskaya 7").Count(&models.Points{})
+	as.NoError(err)
+	as.Equal(1, count)
+
+	res = req.Post(dto.PointInput{Name: "Tverskaya 9", City: "Moscow", OwnerID: 5})
+	as.Equal(422, res.Code)
+	body := dto.Error{}
+	res.Bind(&body)
+	as.Equal(string(models.CodeKeyReused), body.Error.Code)
+}
+
+func (as *ActionSuite) Test_Idempotency_FailedRequestIsNotStored() {
+	req := as.JSON("/api/v1/points")
+	req.Headers["Authorization"] = "Bearer " + as.apiToken()
+	req.Headers["Idempotency-Key"] = "3f1c2a4e-invalid-first"
+
+	as.Equal(422, req.Post(dto.PointInput{}).Code)
+
+	// the key of the failed request can be used for the corrected one
+	res := req.Post(dto.PointInput{Name: "Tverskaya 7", City: "Moscow", OwnerID: 5})
+	as.Equal(201, res.Code)
+	as.Empty(res.Header().Get("Idempotent-Replayed"))
+}
+
+func Test_RequestClient(t *testing.T) {
+	limiter := ratelimit.New(ratelimit.NewMemoryStore())
+	limiter.ProxyHeader = "X-Forwarded-For"
+
+	// the clients behind the proxy are told apart by the address it adds
+	for _, forwarded := range []string{"203.0.113.7", "198.51.100.2"} {
+		r := httptest.NewRequest("POST", "/points", nil)
+		r.RemoteAddr = "10.0.0.1:51234"
+		r.Header.Set("X-Forwarded-For", "192.0.2.1, "+forwarded)
+		if got := requestClient(r, limiter.Address); got != "ip:"+forwarded {
+			t.Errorf("got %q, want ip:%s", got, forwarded)
+		}
+	}
+
+	r := httptest.NewRequest("POST", "/api/v1/points", nil)
+	r.Header.Set("Authorization", "Bearer secret")
+	if got := requestClient(r, limiter.Address); got != "token:"+models.HashToken("secret") {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,34 @@
+package actions
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/buffalo/render"
+	"github.com/gobuffalo/packr/v2"
+)
+
+// openAPIBox holds the OpenAPI document. Every route of App must be listed
+// in it, see Test_OpenAPI_Routes.
+var openAPIBox = packr.New("app:openapi", "../openapi")
+
+// OpenAPI serves the OpenAPI 3 document of the app. This function is mapped
+// to the path GET /api/openapi.json
+func OpenAPI(c buffalo.Context) error {
+	spec, err := openAPIBox.Find("openapi.json")
+	if err != nil {
+		return err
+	}
+
+	return c.Render(http.StatusOK, r.Func("application/json", func(w io.Writer, d render.Data) error {
+		_, err := w.Write(spec)
+		return err
+	}))
+}
+
+// APIDocs renders the OpenAPI document with Redoc. This function is mapped
+// to the path GET /api/docs
+func APIDocs(c buffalo.Context) error {
+	return c.Render(http.StatusOK, r.HTML("api/docs.html"))
+}
@@ -0,0 +1,70 @@
+package actions
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// undocumentedRoutes are the HTML-only pages left out of the OpenAPI document.
+var undocumentedRoutes = map[string]bool{
+	"GET /":                            true,
+	"GET /api/docs":                    true,
+	"GET /points/new":                  true,
+	"GET /points/{point_id}/edit":      true,
+	"GET /companies/new":               true,
+	"GET /companies/{company_id}/edit": true,
+	"GET /users/new":                   true,
+	"GET /users/{user_id}/edit":        true,
+
+	"GET /webhooks":                   true,
+	"GET /webhooks/new":               true,
+	"POST /webhooks":                  true,
+	"GET /webhooks/{webhook_id}":      true,
+	"GET /webhooks/{webhook_id}/edit": true,
+	"PUT /webhooks/{webhook_id}":      true,
+	"DELETE /webhooks/{webhook_id}":   true,
+	"POST /webhooks/{webhook_id}/deliveries/{delivery_id}/resend": true,
+}
+
+func Test_OpenAPI_Routes(t *testing.T) {
+	b, err := openAPIBox.Find("openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}{}
+	if err := json.Unmarshal(b, &spec); err != nil {
+		t.Fatal(err)
+	}
+
+	documented := map[string]bool{}
+	for path, operations := range spec.Paths {
+		for method := range operations {
+			if method != "parameters" {
+				documented[strings.ToUpper(method)+" "+path] = true
+			}
+		}
+	}
+
+	routes := map[string]bool{}
+	for _, route := range App().Routes() {
+		path := route.Path
+		if path != "/" {
+			path = strings.TrimSuffix(path, "/")
+		}
+		key := route.Method + " " + path
+		routes[key] = true
+
+		if !documented[key] && !undocumentedRoutes[key] {
+			t.Errorf("%s is missing from openapi/openapi.json", key)
+		}
+	}
+
+	for key := range documented {
+		if !routes[key] {
+			t.Errorf("%s is documented but not routed", key)
+		}
+	}
+}
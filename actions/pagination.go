@@ -0,0 +1,145 @@
+package actions
+
+import (
+	"encoding/xml"
+	"fmt"
+	"location_service_v1/ls_v2/repository"
+	"strconv"
+	"strings"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/pop"
+)
+
+// listEnvelope is the body of every JSON and XML list response.
+type listEnvelope struct {
+	XMLName xml.Name    `json:"-" xml:"list"`
+	Data    interface{} `json:"data" xml:"data"`
+	Meta    interface{} `json:"meta" xml:"meta"`
+	Links   listLinks   `json:"links" xml:"links"`
+}
+
+// pageMeta describes a list paginated with "page" and "per_page".
+type pageMeta struct {
+	Total      int `json:"total" xml:"total"`
+	Page       int `json:"page" xml:"page"`
+	PerPage    int `json:"per_page" xml:"per_page"`
+	TotalPages int `json:"total_pages" xml:"total_pages"`
+}
+
+// cursorMeta describes a list paginated with keyset cursors.
+type cursorMeta struct {
+	Limit      int    `json:"limit" xml:"limit"`
+	NextCursor string `json:"next_cursor,omitempty" xml:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty" xml:"prev_cursor,omitempty"`
+}
+
+// listLinks are the URLs of the current and the neighbouring pages.
+type listLinks struct {
+	Self  string `json:"self" xml:"self"`
+	First string `json:"first,omitempty" xml:"first,omitempty"`
+	Prev  string `json:"prev,omitempty" xml:"prev,omitempty"`
+	Next  string `json:"next,omitempty" xml:"next,omitempty"`
+	Last  string `json:"last,omitempty" xml:"last,omitempty"`
+}
+
+// pageList wraps a list paginated by p and sets the X-Total-Count and
+// Link headers.
+func pageList(c buffalo.Context, data interface{}, p *pop.Paginator) listEnvelope {
+	links := listLinks{Self: c.Request().URL.String()}
+	if p.TotalPages > 0 {
+		links.First = withParam(c, "page", "1")
+		links.Last = withParam(c, "page", strconv.Itoa(p.TotalPages))
+	}
+	if p.Page > 1 {
+		links.Prev = withParam(c, "page", strconv.Itoa(p.Page-1))
+	}
+	if p.Page < p.TotalPages {
+		links.Next = withParam(c, "page", strconv.Itoa(p.Page+1))
+	}
+
+	c.Response().Header().Set("X-Total-Count", strconv.Itoa(p.TotalEntriesSize))
+	setLinkHeader(c, links)
+
+	return listEnvelope{
+		Data: data,
+		Meta: pageMeta{
+			Total:      p.TotalEntriesSize,
+			Page:       p.Page,
+			PerPage:    p.PerPage,
+			TotalPages: p.TotalPages,
+		},
+		Links: links,
+	}
+}
+
+// cursorList wraps a keyset page and sets the Link header.
+func cursorList(c buffalo.Context, data interface{}, page *repository.CursorPage) listEnvelope {
+	links := listLinks{Self: c.Request().URL.String()}
+	if page.NextCursor != "" {
+		links.Next = withCursor(c, "after", page.NextCursor)
+	}
+	if page.PrevCursor != "" {
+		links.Prev = withCursor(c, "before", page.PrevCursor)
+	}
+
+	setLinkHeader(c, links)
+
+	return listEnvelope{
+		Data: data,
+		Meta: cursorMeta{
+			Limit:      page.Limit,
+			NextCursor: page.NextCursor,
+			PrevCursor: page.PrevCursor,
+		},
+		Links: links,
+	}
+}
+
+// wantsCursor reports whether a JSON or XML client asked for keyset
+// pagination. HTML pages keep the "page" and "per_page" paginator.
+func wantsCursor(c buffalo.Context) bool {
+	if !repository.CursorRequested(c.Params()) {
+		return false
+	}
+	format := requestFormat(c)
+	return format == "json" || format == "xml"
+}
+
+// setLinkHeader adds the RFC 8288 Link header for the neighbouring pages.
+func setLinkHeader(c buffalo.Context, links listLinks) {
+	rels := []string{}
+	for _, l := range []struct{ rel, url string }{
+		{"first", links.First},
+		{"prev", links.Prev},
+		{"next", links.Next},
+		{"last", links.Last},
+	} {
+		if l.url != "" {
+			rels = append(rels, fmt.Sprintf(`<%s>; rel="%s"`, l.url, l.rel))
+		}
+	}
+	if len(rels) > 0 {
+		c.Response().Header().Set("Link", strings.Join(rels, ", "))
+	}
+}
+
+// withCursor is the request URL moved to the keyset page at the cursor.
+func withCursor(c buffalo.Context, direction string, cursor string) string {
+	u := *c.Request().URL
+	query := u.Query()
+	query.Del("after")
+	query.Del("before")
+	query.Set(direction, cursor)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// withParam is the request URL with one query parameter replaced.
+func withParam(c buffalo.Context, name string, value string) string {
+	u := *c.Request().URL
+	query := u.Query()
+	query.Set(name, value)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
@@ -1,7 +1,7 @@
 package actions
 
 import (
-	"fmt"
+	"location_service_v1/ls_v2/models"
 	"location_service_v1/ls_v2/service"
 	"net/http"
 
@@ -28,20 +28,115 @@ func NewPointResource(pointsService *service.PointsService, companiesService *se
 // GET /points
 func (v PointsResource) List(c buffalo.Context) error {
 
+	if wantsCursor(c) {
+		return v.scroll(c)
+	}
+	if wantsExport(c) {
+		return v.export(c)
+	}
+
 	points, q, err := v.pointsService.List(c)
 	if err != nil {
 		return err
 	}
 
 	return responder.Wants("html", func(c buffalo.Context) error {
+		companies, err := v.companiesService.All(c)
+		if err != nil {
+			return err
+		}
+
+		// show company names instead of their ids
+		if err := v.pointsService.LoadCompanies(c, *points); err != nil {
+			return err
+		}
+
 		// Add the paginator to the context so it can be used in the template.
 		c.Set("pagination", q.Paginator)
 		c.Set("points", points)
+		c.Set("companies", companies)
+		c.Set("downloads", downloadLinks(c))
 		return c.Render(http.StatusOK, r.HTML("/points/index.plush.html"))
 	}).Wants("json", func(c buffalo.Context) error {
-		return c.Render(200, r.JSON(points))
+		return c.Render(200, r.JSON(pageList(c, sparse(c, points), q.Paginator)))
 	}).Wants("xml", func(c buffalo.Context) error {
-		return c.Render(200, r.XML(points))
+		return c.Render(200, r.XML(pageList(c, sparse(c, points), q.Paginator)))
+	}).Respond(c)
+}
+
+// Search finds Points by name, address, city and description.
+// This function is mapped to the path GET /points/search
+func (v PointsResource) Search(c buffalo.Context) error {
+
+	results, q, err := v.pointsService.Search(c)
+	if err != nil {
+		return err
+	}
+
+	return responder.Wants("html", func(c buffalo.Context) error {
+		// Add the paginator to the context so it can be used in the template.
+		c.Set("pagination", q.Paginator)
+		c.Set("results", results)
+		return c.Render(http.StatusOK, r.HTML("/points/search.plush.html"))
+	}).Wants("json", func(c buffalo.Context) error {
+		return c.Render(200, r.JSON(pageList(c, results, q.Paginator)))
+	}).Wants("xml", func(c buffalo.Context) error {
+		return c.Render(200, r.XML(pageList(c, results, q.Paginator)))
+	}).Respond(c)
+}
+
+// Changes gets the points created, updated and deleted since the "since"
+// token, for clients that keep a copy of the points in sync. Each page
+// has the token of the next request; an empty page means the client is
+// up to date. This function is mapped to the path GET /points/changes
+func (v PointsResource) Changes(c buffalo.Context) error {
+
+	feed, err := v.pointsService.Changes(c)
+	if err != nil {
+		return err
+	}
+
+	return responder.Wants("json", func(c buffalo.Context) error {
+		return c.Render(200, r.JSON(feed))
+	}).Wants("xml", func(c buffalo.Context) error {
+		return c.Render(200, r.XML(feed))
+	}).Respond(c)
+}
+
+// Autocomplete suggests cities, addresses or point names for typeahead
+// widgets. This function is mapped to the path GET /autocomplete
+func (v PointsResource) Autocomplete(c buffalo.Context) error {
+
+	suggestions, err := v.pointsService.Autocomplete(c)
+	if err != nil {
+		return err
+	}
+
+	return responder.Wants("json", func(c buffalo.Context) error {
+		return c.Render(200, r.JSON(suggestions))
+	}).Wants("xml", func(c buffalo.Context) error {
+		return c.Render(200, r.XML(suggestions))
+	}).Wants("html", func(c buffalo.Context) error {
+		// widgets often call it without an Accept header
+		return c.Render(200, r.JSON(suggestions))
+	}).Respond(c)
+}
+
+// scroll renders a keyset paginated page of points for JSON and XML
+// clients that use "after", "before" or "limit"
+func (v PointsResource) scroll(c buffalo.Context) error {
+
+	points, page, err := v.pointsService.Scroll(c)
+	if err != nil {
+		return err
+	}
+
+	list := cursorList(c, sparse(c, points), page)
+
+	return responder.Wants("json", func(c buffalo.Context) error {
+		return c.Render(200, r.JSON(list))
+	}).Wants("xml", func(c buffalo.Context) error {
+		return c.Render(200, r.XML(list))
 	}).Respond(c)
 }
 
@@ -53,15 +148,23 @@ func (v PointsResource) Show(c buffalo.Context) error {
 	if err != nil {
 		return err
 	}
+	if notModified(c, point.Version) {
+		return c.Render(http.StatusNotModified, nil)
+	}
 
 	return responder.Wants("html", func(c buffalo.Context) error {
-		c.Set("point", point)
+		// show the company name instead of its id
+		points := models.Points{*point}
+		if err := v.pointsService.LoadCompanies(c, points); err != nil {
+			return err
+		}
+		c.Set("point", points[0])
 
 		return c.Render(http.StatusOK, r.HTML("/points/show.plush.html"))
 	}).Wants("json", func(c buffalo.Context) error {
-		return c.Render(200, r.JSON(point))
+		return c.Render(200, r.JSON(sparse(c, point)))
 	}).Wants("xml", func(c buffalo.Context) error {
-		return c.Render(200, r.XML(point))
+		return c.Render(200, r.XML(sparse(c, point)))
 	}).Respond(c)
 
 }
@@ -70,9 +173,9 @@ func (v PointsResource) Show(c buffalo.Context) error {
 // This function is mapped to the path GET /points/new
 func (v PointsResource) New(c buffalo.Context) error {
 	point := v.pointsService.New(c)
-	companies, _, _ := v.companiesService.List(c)
-	if point == nil {
-		return fmt.Errorf("somthing goes worng")
+	companies, err := v.companiesService.All(c)
+	if err != nil {
+		return err
 	}
 	c.Set("point", point)
 	c.Set("companies", companies)
@@ -99,9 +202,9 @@ func (v PointsResource) Create(c buffalo.Context) error {
 
 			return c.Render(http.StatusUnprocessableEntity, r.HTML("/points/new.plush.html"))
 		}).Wants("json", func(c buffalo.Context) error {
-			return c.Render(http.StatusUnprocessableEntity, r.JSON(verrs))
+			return models.ValidationError(verrs)
 		}).Wants("xml", func(c buffalo.Context) error {
-			return c.Render(http.StatusUnprocessableEntity, r.XML(verrs))
+			return models.ValidationError(verrs)
 		}).Respond(c)
 	}
 
@@ -112,8 +215,10 @@ func (v PointsResource) Create(c buffalo.Context) error {
 		// and redirect to the show page
 		return c.Redirect(http.StatusSeeOther, "/points/%v", point.ID)
 	}).Wants("json", func(c buffalo.Context) error {
+		setETag(c, point.Version)
 		return c.Render(http.StatusCreated, r.JSON(point))
 	}).Wants("xml", func(c buffalo.Context) error {
+		setETag(c, point.Version)
 		return c.Render(http.StatusCreated, r.XML(point))
 	}).Respond(c)
 }
@@ -122,7 +227,10 @@ func (v PointsResource) Create(c buffalo.Context) error {
 // mapped to the path GET /points/{point_id}/edit
 func (v PointsResource) Edit(c buffalo.Context) error {
 	point, err := v.pointsService.Edit(c)
-	companies, _, _ := v.companiesService.List(c)
+	if err != nil {
+		return err
+	}
+	companies, err := v.companiesService.All(c)
 	if err != nil {
 		return err
 	}
@@ -136,6 +244,9 @@ func (v PointsResource) Edit(c buffalo.Context) error {
 func (v PointsResource) Update(c buffalo.Context) error {
 
 	verrs, point, err := v.pointsService.Update(c)
+	if isConflict(err) && requestFormat(c) == "html" {
+		return v.conflict(c, point)
+	}
 	if err != nil {
 		return err
 	}
@@ -151,9 +262,9 @@ func (v PointsResource) Update(c buffalo.Context) error {
 
 			return c.Render(http.StatusUnprocessableEntity, r.HTML("/points/edit.plush.html"))
 		}).Wants("json", func(c buffalo.Context) error {
-			return c.Render(http.StatusUnprocessableEntity, r.JSON(verrs))
+			return models.ValidationError(verrs)
 		}).Wants("xml", func(c buffalo.Context) error {
-			return c.Render(http.StatusUnprocessableEntity, r.XML(verrs))
+			return models.ValidationError(verrs)
 		}).Respond(c)
 	}
 
@@ -164,8 +275,51 @@ func (v PointsResource) Update(c buffalo.Context) error {
 		// and redirect to the show page
 		return c.Redirect(http.StatusSeeOther, "/points/%v", point.ID)
 	}).Wants("json", func(c buffalo.Context) error {
+		setETag(c, point.Version)
+		return c.Render(http.StatusOK, r.JSON(point))
+	}).Wants("xml", func(c buffalo.Context) error {
+		setETag(c, point.Version)
+		return c.Render(http.StatusOK, r.XML(point))
+	}).Respond(c)
+}
+
+// conflict renders the edit form again when the Point was changed by
+// someone else since the form was opened. The page shows the saved values
+// next to the user's, which are saved over the new version when sent again.
+func (v PointsResource) conflict(c buffalo.Context, current *models.Point) error {
+	mine := *current
+	if err := c.Bind(&mine); err != nil {
+		return err
+	}
+	companies, err := v.companiesService.All(c)
+	if err != nil {
+		return err
+	}
+
+	c.Set("current", current)
+	c.Set("point", &mine)
+	c.Set("companies", companies)
+	return c.Render(http.StatusConflict, r.HTML("/points/conflict.plush.html"))
+}
+
+// Patch changes only the fields of a Point that the body sends, as a
+// JSON Merge Patch or, with the application/json-patch+json type, a JSON
+// Patch. This function is mapped to the path PATCH /points/{point_id}
+func (v PointsResource) Patch(c buffalo.Context) error {
+
+	verrs, point, err := v.pointsService.Patch(c)
+	if err != nil {
+		return err
+	}
+	if verrs.HasAny() {
+		return models.ValidationError(verrs)
+	}
+
+	return responder.Wants("json", func(c buffalo.Context) error {
+		setETag(c, point.Version)
 		return c.Render(http.StatusOK, r.JSON(point))
 	}).Wants("xml", func(c buffalo.Context) error {
+		setETag(c, point.Version)
 		return c.Render(http.StatusOK, r.XML(point))
 	}).Respond(c)
 }
@@ -0,0 +1,288 @@
+package actions
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"location_service_v1/ls_v2/models"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/x/responder"
+)
+
+// exportFormats are the formats of the point downloads, in the order of
+// the Download menu, with the part of the Accept header that asks for them.
+var exportFormats = []struct {
+	Format string
+	Accept string
+	Label  string
+}{
+	{"csv", "csv", "CSV"},
+	{"geojson", "geo+json", "GeoJSON"},
+	{"kml", "kml", "KML"},
+}
+
+// wantsExport reports whether the Accept header asks for a download of the
+// points instead of a page.
+func wantsExport(c buffalo.Context) bool {
+	accept := strings.ToLower(c.Request().Header.Get("Accept"))
+	for _, f := range exportFormats {
+		if strings.Contains(accept, f.Accept) {
+			return true
+		}
+	}
+	return false
+}
+
+// downloadLink is an entry of the Download menu of the points page.
+type downloadLink struct {
+	Label string
+	URL   string
+}
+
+// downloadLinks are the export links of the Download menu. They keep the
+// filters of the page and drop its pagination.
+func downloadLinks(c buffalo.Context) []downloadLink {
+	links := []downloadLink{}
+	for _, f := range exportFormats {
+		q := url.Values{}
+		for k, v := range c.Request().URL.Query() {
+			q[k] = v
+		}
+		q.Del("page")
+		q.Del("per_page")
+		q.Set("format", f.Format)
+		links = append(links, downloadLink{Label: f.Label, URL: c.Request().URL.Path + "?" + q.Encode()})
+	}
+	return links
+}
+
+// export streams every point that matches the filters of List as CSV,
+// GeoJSON or KML, without pagination.
+func (v PointsResource) export(c buffalo.Context) error {
+	return responder.Wants("csv", func(c buffalo.Context) error {
+		return v.stream(c, "text/csv; charset=utf-8", "points.csv", &csvExport{})
+	}).Wants("geo+json", func(c buffalo.Context) error {
+		return v.stream(c, "application/geo+json", "points.geojson", &geoJSONExport{})
+	}).Wants("kml", func(c buffalo.Context) error {
+		return v.stream(c, "application/vnd.google-earth.kml+xml", "points.kml", &kmlExport{})
+	}).Respond(c)
+}
+
+// pointsExport writes points in one of the download formats.
+type pointsExport interface {
+	begin(w io.Writer) error
+	write(points models.Points) error
+	end() error
+}
+
+// stream writes the export batch by batch and flushes after each one. The
+// response starts with the first batch, so a bad filter is still answered
+// with an error status. A failure after that can only be logged: the
+// download is cut short.
+func (v PointsResource) stream(c buffalo.Context, contentType, filename string, e pointsExport) error {
+	res := c.Response()
+	started := false
+	start := func() error {
+		started = true
+		res.Header().Set("Content-Type", contentType)
+		res.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+		res.WriteHeader(http.StatusOK)
+		return e.begin(res)
+	}
+
+	err := v.pointsService.Export(c, func(points models.Points) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		if err := e.write(points); err != nil {
+			return err
+		}
+		if f, ok := res.(http.Flusher); ok {
+			f.Flush()
+		}
+		return nil
+	})
+	if err != nil && !started {
+		return err
+	}
+	if err == nil && !started {
+		err = start()
+	}
+	if err == nil {
+		err = e.end()
+	}
+	if err != nil {
+		c.Logger().Error(err)
+	}
+	return nil
+}
+
+// csvExport writes one row per point after a header row.
+type csvExport struct {
+	w *csv.Writer
+}
+
+func (e *csvExport) begin(w io.Writer) error {
+	e.w = csv.NewWriter(w)
+	return e.w.Write([]string{"id", "name", "point_id", "address", "city", "out_description", "owner_id", "owner_name",
+		"max_length", "max_width", "max_height", "max_weight", "company_id", "company", "created_at", "updated_at"})
+}
+
+func (e *csvExport) write(points models.Points) error {
+	for _, p := range points {
+		row := []string{p.ID.String(), csvText(p.Name), strconv.Itoa(p.PointID), csvText(p.Address), csvText(p.CityName),
+			csvText(p.OutDescription), strconv.Itoa(p.OwnerID), csvText(p.OwnerName), strconv.Itoa(p.MaxLength),
+			strconv.Itoa(p.MaxWidth), strconv.Itoa(p.MaxHeight), strconv.Itoa(p.MaxWeight), p.CompanyID.String(),
+			csvText(p.CompanyName()),
+			p.CreatedAt.Format(time.RFC3339), p.UpdatedAt.Format(time.RFC3339)}
+		if err := e.w.Write(row); err != nil {
+			return err
+		}
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvExport) end() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// csvText escapes a cell of free text. Spreadsheets run a cell that starts
+// with =, +, - or @ (or a tab or carriage return before one) as a formula,
+// so such a cell is prefixed with a quote to be shown as text.
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// geoJSONFeature is a point in a GeoJSON FeatureCollection. Points have no
+// coordinates yet, so the geometry is null as RFC 7946 allows for
+// unlocated features.
+type geoJSONFeature struct {
+	Type       string                 `json:"type"`
+	ID         string                 `json:"id"`
+	Geometry   interface{}            `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// geoJSONExport writes a FeatureCollection with one Feature per point.
+type geoJSONExport struct {
+	w     io.Writer
+	count int
+}
+
+func (e *geoJSONExport) begin(w io.Writer) error {
+	e.w = w
+	_, err := io.WriteString(w, `{"type":"FeatureCollection","features":[`)
+	return err
+}
+
+func (e *geoJSONExport) write(points models.Points) error {
+	for _, p := range points {
+		b, err := json.Marshal(geoJSONFeature{
+			Type: "Feature",
+			ID:   p.ID.String(),
+			Properties: map[string]interface{}{
+				"name":            p.Name,
+				"point_id":        p.PointID,
+				"address":         p.Address,
+				"city":            p.CityName,
+				"out_description": p.OutDescription,
+				"owner_id":        p.OwnerID,
+				"owner_name":      p.OwnerName,
+				"company_id":      p.CompanyID,
+				"company":         p.CompanyName(),
+			},
+		})
+		if err != nil {
+			return err
+		}
+		if e.count > 0 {
+			b = append([]byte(","), b...)
+		}
+		if _, err := e.w.Write(b); err != nil {
+			return err
+		}
+		e.count++
+	}
+	return nil
+}
+
+func (e *geoJSONExport) end() error {
+	_, err := io.WriteString(e.w, "]}\n")
+	return err
+}
+
+// kmlPlacemark is a point in a KML document. Without coordinates it has
+// no Point geometry; the address lets KML clients geocode it.
+type kmlPlacemark struct {
+	XMLName     xml.Name  `xml:"Placemark"`
+	ID          string    `xml:"id,attr"`
+	Name        string    `xml:"name"`
+	Address     string    `xml:"address,omitempty"`
+	Description string    `xml:"description,omitempty"`
+	Data        []kmlData `xml:"ExtendedData>Data"`
+}
+
+// kmlData is a named value of the ExtendedData of a Placemark.
+type kmlData struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value"`
+}
+
+// kmlExport writes a KML Document with one Placemark per point.
+type kmlExport struct {
+	w   io.Writer
+	enc *xml.Encoder
+}
+
+func (e *kmlExport) begin(w io.Writer) error {
+	e.w = w
+	e.enc = xml.NewEncoder(w)
+	_, err := io.WriteString(w, xml.Header+`<kml xmlns="http://www.opengis.net/kml/2.2"><Document><name>Points</name>`)
+	return err
+}
+
+func (e *kmlExport) write(points models.Points) error {
+	for _, p := range points {
+		address := []string{}
+		for _, part := range []string{p.Address, p.CityName} {
+			if part != "" {
+				address = append(address, part)
+			}
+		}
+		err := e.enc.Encode(kmlPlacemark{
+			ID:          "point-" + p.ID.String(),
+			Name:        p.Name,
+			Address:     strings.Join(address, ", "),
+			Description: p.OutDescription,
+			Data: []kmlData{
+				{Name: "point_id", Value: strconv.Itoa(p.PointID)},
+				{Name: "owner_id", Value: strconv.Itoa(p.OwnerID)},
+				{Name: "owner_name", Value: p.OwnerName},
+				{Name: "company", Value: p.CompanyName()},
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return e.enc.Flush()
+}
+
+func (e *kmlExport) end() error {
+	_, err := io.WriteString(e.w, "</Document></kml>\n")
+	return err
+}
@@ -1,11 +1,121 @@
 package actions
 
 import (
+	"bytes"
+	"encoding/json"
 	"location_service_v1/ls_v2/models"
+	"strings"
+	"testing"
 )
 
 func (as *ActionSuite) Test_PointsResource_List() {
-	as.Fail("Not Implemented!")
+	as.NoError(as.DB.Create(&models.Point{Name: "Tverskaya 7"}))
+
+	res := as.JSON("/points?per_page=10").Get()
+	as.Equal(200, res.Code)
+	as.Equal("1", res.Header().Get("X-Total-Count"))
+
+	body := struct {
+		Data []models.Point `json:"data"`
+		Meta pageMeta       `json:"meta"`
+	}{}
+	res.Bind(&body)
+	as.Len(body.Data, 1)
+	as.Equal(1, body.Meta.Total)
+	as.Equal(10, body.Meta.PerPage)
+}
+
+func (as *ActionSuite) Test_PointsResource_Export() {
+	as.NoError(as.DB.Create(&models.Point{Name: "Tverskaya 7", CityName: "Moscow"}))
+	as.NoError(as.DB.Create(&models.Point{Name: "Nevsky 1", CityName: "Saint Petersburg"}))
+
+	res := as.HTML("/points?city=moscow&per_page=1&format=csv").Get()
+	as.Equal(200, res.Code)
+	as.Contains(res.Header().Get("Content-Disposition"), "points.csv")
+	as.Contains(res.Body.String(), "id,name,point_id,address,city")
+	as.Contains(res.Body.String(), "Tverskaya 7")
+	as.NotContains(res.Body.String(), "Nevsky 1")
+
+	res = as.HTML("/points?format=geojson").Get()
+	as.Equal(200, res.Code)
+	body := struct {
+		Type     string `json:"type"`
+		Features []struct {
+			Geometry   interface{}            `json:"geometry"`
+			Properties map[string]interface{} `json:"properties"`
+		} `json:"features"`
+	}{}
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &body))
+	as.Equal("FeatureCollection", body.Type)
+	as.Len(body.Features, 2)
+
+	res = as.HTML("/points?format=kml").Get()
+	as.Equal(200, res.Code)
+	as.Contains(res.Body.String(), "<Placemark id=\"point-")
+	as.Contains(res.Body.String(), "</Document></kml>")
+
+	// the Download menu keeps the filters
+	res = as.HTML("/points?city=Moscow&page=2").Get()
+	as.Contains(res.Body.String(), "/points?city=Moscow&amp;format=csv")
+}
+
+func Test_CSVExport_Formulas(t *testing.T) {
+	buf := &bytes.Buffer{}
+	e := &csvExport{}
+	if err := e.begin(buf); err != nil {
+		t.Fatal(err)
+	}
+	points := models.Points{
+		{Name: "=HYPERLINK(\"http://evil.example\",\"Tverskaya 7\")", Address: "+7 495 000", CityName: "-Moscow", OwnerName: "@owner"},
+		{Name: "Nevsky 1", Address: "Nevsky pr. 1", CityName: "Saint Petersburg", OwnerName: "Ivan -2"},
+	}
+	if err := e.write(points); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(buf.String(), "\n")
+	for _, cell := range []string{`"'=HYPERLINK(""http://evil.example"",""Tverskaya 7"")"`, ",'+7 495 000,", ",'-Moscow,", ",'@owner,"} {
+		if !strings.Contains(lines[1], cell) {
+			t.Errorf("%q not in %s", cell, lines[1])
+		}
+	}
+	for _, cell := range []string{",Nevsky 1,", ",Nevsky pr. 1,", ",Ivan -2,"} {
+		if !strings.Contains(lines[2], cell) {
+			t.Errorf("%q not in %s", cell, lines[2])
+		}
+	}
+}
+
+func (as *ActionSuite) Test_PointsResource_Autocomplete() {
+	as.NoError(as.DB.Create(&models.Point{Name: "Tverskaya 7", Address: "Tverskaya st. 7", CityName: "Moscow"}))
+	as.NoError(as.DB.Create(&models.Point{Name: "Tverskaya 12", Address: "Tverskaya st. 12", CityName: "Moscow"}))
+	as.NoError(as.DB.Create(&models.Point{Name: "Nevsky 1", Address: "Nevsky pr. 1", CityName: "Saint Petersburg"}))
+
+	suggest := func(query string) models.Suggestions {
+		res := as.JSON("/autocomplete?%s", query).Get()
+		as.Equal(200, res.Code)
+		suggestions := models.Suggestions{}
+		res.Bind(&suggestions)
+		return suggestions
+	}
+
+	// cities are grouped with their number of points
+	as.Equal(models.Suggestions{{Value: "Moscow", Points: 2}}, suggest("q=mos"))
+
+	// three characters or more match inside the value
+	addresses := suggest("type=address&q=skaya")
+	as.Len(addresses, 2)
+	as.Contains(addresses[0].Value, "Tverskaya st.")
+
+	names := suggest("type=point&q=nevs")
+	as.Equal(models.Suggestions{{Value: "Nevsky 1", Points: 1}}, names)
+
+	// shorter queries only match the start of the value
+	as.Equal(models.Suggestions{{Value: "Saint Petersburg", Points: 1}}, suggest("q=sa"))
+	as.Empty(suggest("q=ow"))
+	as.Empty(suggest("q="))
+
+	as.Equal(400, as.JSON("/autocomplete?type=owner&q=mos").Get().Code)
 }
 
 func (as *ActionSuite) Test_PointsResource_Show() {
@@ -17,7 +127,22 @@ func (as *ActionSuite) Test_PointsResource_Create() {
 }
 
 func (as *ActionSuite) Test_PointsResource_Update() {
-	as.Fail("Not Implemented!")
+	point := &models.Point{Name: "Tverskaya 7", CityName: "Moscow"}
+	as.NoError(as.DB.Create(point))
+
+	res := as.HTML("/points/%s", point.ID).Put(map[string]string{"Name": "Tverskaya 7/2", "version": "0"})
+	as.Equal(303, res.Code)
+
+	// the form was opened before the first save
+	res = as.HTML("/points/%s", point.ID).Put(map[string]string{"Name": "Tverskaya 9", "version": "0"})
+	as.Equal(409, res.Code)
+	as.Contains(res.Body.String(), "changed by someone else")
+	as.Contains(res.Body.String(), "Tverskaya 7/2")
+	as.Contains(res.Body.String(), `name="version" value="1"`)
+
+	as.NoError(as.DB.Reload(point))
+	as.Equal("Tverskaya 7/2", point.Name)
+	as.Equal(1, point.Version)
 }
 
 func (as *ActionSuite) Test_PointsResource_Destroy() {
@@ -29,7 +154,75 @@ func (as *ActionSuite) Test_PointsResource_New() {
 }
 
 func (as *ActionSuite) Test_PointsResource_Edit() {
-	as.Fail("Not Implemented!")
+	point := &models.Point{Name: "Tverskaya 7"}
+	as.NoError(as.DB.Create(point))
+
+	// the form is only read, a stale If-Match is checked when it is sent
+	req := as.HTML("/points/%s/edit", point.ID)
+	req.Headers["If-Match"] = models.ETag(point.Version + 1)
+	res := req.Get()
+	as.Equal(200, res.Code)
+	as.Contains(res.Body.String(), "Tverskaya 7")
+}
+
+func (as *ActionSuite) Test_PointsResource_Changes() {
+	a := &models.Point{Name: "Tverskaya 7"}
+	as.NoError(as.DB.Create(a))
+	b := &models.Point{Name: "Arbat 1"}
+	as.NoError(as.DB.Create(b))
+
+	feed := models.PointChangeFeed{}
+	res := as.JSON("/points/changes").Get()
+	as.Equal(200, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &feed))
+	as.Len(feed.Upserts, 2)
+	as.Empty(feed.Deletions)
+	as.False(feed.HasMore)
+
+	a.Name = "Tverskaya 7/2"
+	as.NoError(as.DB.Update(a))
+	as.NoError(as.DB.Destroy(b))
+
+	since := feed.Next
+	feed = models.PointChangeFeed{}
+	res = as.JSON("/points/changes?since=%s", since).Get()
+	as.Equal(200, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &feed))
+	as.Len(feed.Upserts, 1)
+	as.Equal("Tverskaya 7/2", feed.Upserts[0].Name)
+	as.Len(feed.Deletions, 1)
+	as.Equal(b.ID, feed.Deletions[0].ID)
+
+	// up to date
+	since = feed.Next
+	feed = models.PointChangeFeed{}
+	res = as.JSON("/points/changes?since=%s", since).Get()
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &feed))
+	as.Empty(feed.Upserts)
+	as.Equal(since, feed.Next)
+
+	res = as.JSON("/points/changes?since=broken").Get()
+	as.Equal(400, res.Code)
+}
+
+func (as *ActionSuite) Test_PointsResource_Patch() {
+	point := &models.Point{Name: "Tverskaya 7", PointID: 42, OwnerID: 5}
+	as.NoError(as.DB.Create(point))
+
+	req := as.JSON("/points/%s", point.ID)
+	req.Headers["Content-Type"] = "application/merge-patch+json"
+	res := req.Patch(map[string]interface{}{"address": "Tverskaya 7/2", "ownerName": nil})
+	as.Equal(200, res.Code)
+
+	// the fields left out keep their values
+	as.NoError(as.DB.Reload(point))
+	as.Equal("Tverskaya 7/2", point.Address)
+	as.Equal(42, point.PointID)
+	as.Equal(5, point.OwnerID)
+
+	as.Equal(422, req.Patch(map[string]interface{}{"ownerId": "five"}).Code)
+	as.Equal(422, req.Patch(map[string]interface{}{"name": nil}).Code)
+	as.Equal(400, req.Patch(map[string]interface{}{"unknown": 1}).Code)
 }
 
 func (as *ActionSuite) Test_GetPickPointsList() {
@@ -0,0 +1,76 @@
+package actions
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"io"
+	"location_service_v1/ls_v2/models"
+	"location_service_v1/ls_v2/service"
+	"strconv"
+	"time"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/buffalo/render"
+	"github.com/gobuffalo/x/responder"
+)
+
+// StatsResource serves aggregated statistics
+type StatsResource struct {
+	statsService *service.StatsService
+}
+
+// NewStatsResource is a
+func NewStatsResource(statsService *service.StatsService) *StatsResource {
+	return &StatsResource{
+		statsService: statsService,
+	}
+}
+
+// pointStatsBody is the JSON and XML body of the point statistics.
+type pointStatsBody struct {
+	XMLName xml.Name           `json:"-" xml:"stats"`
+	Since   time.Time          `json:"since" xml:"since"`
+	GroupBy string             `json:"group_by" xml:"group_by"`
+	Data    *models.PointStats `json:"data" xml:"data"`
+}
+
+// Points counts points by company and city, with the changes since a date.
+// This function is mapped to the path GET /stats/points
+func (v StatsResource) Points(c buffalo.Context) error {
+
+	stats, since, err := v.statsService.Points(c)
+	if err != nil {
+		return err
+	}
+
+	body := pointStatsBody{Since: since, GroupBy: c.Param("group_by"), Data: stats}
+
+	return responder.Wants("json", func(c buffalo.Context) error {
+		return c.Render(200, r.JSON(body))
+	}).Wants("xml", func(c buffalo.Context) error {
+		return c.Render(200, r.XML(body))
+	}).Wants("csv", func(c buffalo.Context) error {
+		c.Response().Header().Set("Content-Disposition", `attachment; filename="point-stats.csv"`)
+		return c.Render(200, r.Func("text/csv", func(w io.Writer, d render.Data) error {
+			return writePointStatsCSV(w, *stats)
+		}))
+	}).Wants("html", func(c buffalo.Context) error {
+		return c.Render(200, r.JSON(body))
+	}).Respond(c)
+}
+
+// writePointStatsCSV writes one row per group after a header row.
+func writePointStatsCSV(w io.Writer, stats models.PointStats) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"company_id", "company", "city", "points", "created", "updated"}); err != nil {
+		return err
+	}
+	for _, s := range stats {
+		row := []string{s.CompanyID, csvText(s.Company), csvText(s.City), strconv.Itoa(s.Points), strconv.Itoa(s.Created), strconv.Itoa(s.Updated)}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
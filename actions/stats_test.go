@@ -0,0 +1,28 @@
+package actions
+
+import (
+	"location_service_v1/ls_v2/models"
+)
+
+func (as *ActionSuite) Test_StatsResource_Points() {
+	company := &models.Company{Name: "pickpoint"}
+	as.NoError(as.DB.Create(company))
+	as.NoError(as.DB.Create(&models.Point{Name: "1", CityName: "Moscow", CompanyID: company.ID}))
+	as.NoError(as.DB.Create(&models.Point{Name: "2", CityName: "Moscow", CompanyID: company.ID}))
+	as.NoError(as.DB.Create(&models.Point{Name: "3", CityName: "Tver", CompanyID: company.ID}))
+
+	body := pointStatsBody{}
+	res := as.JSON("/stats/points?group_by=company,city").Get()
+	as.Equal(200, res.Code)
+	res.Bind(&body)
+	as.Len(*body.Data, 2)
+	as.Equal(2, (*body.Data)[0].Points)
+	as.Equal(2, (*body.Data)[0].Created)
+
+	csv := as.HTML("/stats/points?group_by=city&format=csv").Get()
+	as.Equal(200, csv.Code)
+	as.Contains(csv.Body.String(), "company_id,company,city,points,created,updated")
+
+	res = as.JSON("/stats/points?group_by=status").Get()
+	as.Equal(400, res.Code)
+}
@@ -1,9 +1,9 @@
 package actions
 
 import (
-	"fmt"
 	"github.com/gobuffalo/buffalo"
 	"github.com/gobuffalo/x/responder"
+	"location_service_v1/ls_v2/models"
 	"location_service_v1/ls_v2/service"
 	"net/http"
 )
@@ -36,6 +36,10 @@ func NewUserResource(service *service.UsersService) *UsersResource {
 // GET /users
 func (v UsersResource) List(c buffalo.Context) error {
 
+	if wantsCursor(c) {
+		return v.scroll(c)
+	}
+
 	users, q, err := v.usersService.List(c)
 	if err != nil {
 		return err
@@ -48,9 +52,27 @@ func (v UsersResource) List(c buffalo.Context) error {
 		c.Set("users", users)
 		return c.Render(http.StatusOK, r.HTML("/users/index.plush.html"))
 	}).Wants("json", func(c buffalo.Context) error {
-		return c.Render(200, r.JSON(users))
+		return c.Render(200, r.JSON(pageList(c, sparse(c, users), q.Paginator)))
 	}).Wants("xml", func(c buffalo.Context) error {
-		return c.Render(200, r.XML(users))
+		return c.Render(200, r.XML(pageList(c, sparse(c, users), q.Paginator)))
+	}).Respond(c)
+}
+
+// scroll renders a keyset paginated page of users for JSON and XML
+// clients that use "after", "before" or "limit"
+func (v UsersResource) scroll(c buffalo.Context) error {
+
+	users, page, err := v.usersService.Scroll(c)
+	if err != nil {
+		return err
+	}
+
+	list := cursorList(c, sparse(c, users), page)
+
+	return responder.Wants("json", func(c buffalo.Context) error {
+		return c.Render(200, r.JSON(list))
+	}).Wants("xml", func(c buffalo.Context) error {
+		return c.Render(200, r.XML(list))
 	}).Respond(c)
 }
 
@@ -63,15 +85,18 @@ func (v UsersResource) Show(c buffalo.Context) error {
 	if err != nil {
 		return err
 	}
+	if notModified(c, user.Version) {
+		return c.Render(http.StatusNotModified, nil)
+	}
 
 	return responder.Wants("html", func(c buffalo.Context) error {
 		c.Set("user", user)
 
 		return c.Render(http.StatusOK, r.HTML("/users/show.plush.html"))
 	}).Wants("json", func(c buffalo.Context) error {
-		return c.Render(200, r.JSON(user))
+		return c.Render(200, r.JSON(sparse(c, user)))
 	}).Wants("xml", func(c buffalo.Context) error {
-		return c.Render(200, r.XML(user))
+		return c.Render(200, r.XML(sparse(c, user)))
 	}).Respond(c)
 }
 
@@ -80,10 +105,6 @@ func (v UsersResource) Show(c buffalo.Context) error {
 func (v UsersResource) New(c buffalo.Context) error {
 
 	user := v.usersService.New(c)
-	if user == nil {
-		return fmt.Errorf("somthing goes worng")
-	}
-
 	c.Set("user", user)
 
 	return c.Render(http.StatusOK, r.HTML("/users/new.plush.html"))
@@ -109,9 +130,9 @@ func (v UsersResource) Create(c buffalo.Context) error {
 
 			return c.Render(http.StatusUnprocessableEntity, r.HTML("/users/new.plush.html"))
 		}).Wants("json", func(c buffalo.Context) error {
-			return c.Render(http.StatusUnprocessableEntity, r.JSON(verrs))
+			return models.ValidationError(verrs)
 		}).Wants("xml", func(c buffalo.Context) error {
-			return c.Render(http.StatusUnprocessableEntity, r.XML(verrs))
+			return models.ValidationError(verrs)
 		}).Respond(c)
 	}
 
@@ -122,8 +143,10 @@ func (v UsersResource) Create(c buffalo.Context) error {
 		// and redirect to the show page
 		return c.Redirect(http.StatusSeeOther, "/users/%v", user.ID)
 	}).Wants("json", func(c buffalo.Context) error {
+		setETag(c, user.Version)
 		return c.Render(http.StatusCreated, r.JSON(user))
 	}).Wants("xml", func(c buffalo.Context) error {
+		setETag(c, user.Version)
 		return c.Render(http.StatusCreated, r.XML(user))
 	}).Respond(c)
 }
@@ -146,6 +169,9 @@ func (v UsersResource) Edit(c buffalo.Context) error {
 func (v UsersResource) Update(c buffalo.Context) error {
 
 	verrs, user, err := v.usersService.Update(c)
+	if isConflict(err) && requestFormat(c) == "html" {
+		return v.conflict(c, user)
+	}
 	if err != nil {
 		return err
 	}
@@ -161,9 +187,9 @@ func (v UsersResource) Update(c buffalo.Context) error {
 
 			return c.Render(http.StatusUnprocessableEntity, r.HTML("/users/edit.plush.html"))
 		}).Wants("json", func(c buffalo.Context) error {
-			return c.Render(http.StatusUnprocessableEntity, r.JSON(verrs))
+			return models.ValidationError(verrs)
 		}).Wants("xml", func(c buffalo.Context) error {
-			return c.Render(http.StatusUnprocessableEntity, r.XML(verrs))
+			return models.ValidationError(verrs)
 		}).Respond(c)
 	}
 
@@ -174,8 +200,46 @@ func (v UsersResource) Update(c buffalo.Context) error {
 		// and redirect to the show page
 		return c.Redirect(http.StatusSeeOther, "/users/%v", user.ID)
 	}).Wants("json", func(c buffalo.Context) error {
+		setETag(c, user.Version)
+		return c.Render(http.StatusOK, r.JSON(user))
+	}).Wants("xml", func(c buffalo.Context) error {
+		setETag(c, user.Version)
+		return c.Render(http.StatusOK, r.XML(user))
+	}).Respond(c)
+}
+
+// conflict renders the edit form again when the User was changed by
+// someone else since the form was opened. The page shows the saved values
+// next to the user's, which are saved over the new version when sent again.
+func (v UsersResource) conflict(c buffalo.Context, current *models.User) error {
+	mine := *current
+	if err := c.Bind(&mine); err != nil {
+		return err
+	}
+
+	c.Set("current", current)
+	c.Set("user", &mine)
+	return c.Render(http.StatusConflict, r.HTML("/users/conflict.plush.html"))
+}
+
+// Patch changes only the fields of a User that the body sends, as a
+// JSON Merge Patch or, with the application/json-patch+json type, a JSON
+// Patch. This function is mapped to the path PATCH /users/{user_id}
+func (v UsersResource) Patch(c buffalo.Context) error {
+
+	verrs, user, err := v.usersService.Patch(c)
+	if err != nil {
+		return err
+	}
+	if verrs.HasAny() {
+		return models.ValidationError(verrs)
+	}
+
+	return responder.Wants("json", func(c buffalo.Context) error {
+		setETag(c, user.Version)
 		return c.Render(http.StatusOK, r.JSON(user))
 	}).Wants("xml", func(c buffalo.Context) error {
+		setETag(c, user.Version)
 		return c.Render(http.StatusOK, r.XML(user))
 	}).Respond(c)
 }
@@ -0,0 +1,152 @@
+package actions
+
+import (
+	"location_service_v1/ls_v2/models"
+	"location_service_v1/ls_v2/service"
+	"net/http"
+	"strings"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/validate"
+)
+
+// WebhooksResource manages the webhook subscriptions of partner systems
+// and shows their delivery logs. It only has HTML pages.
+type WebhooksResource struct {
+	buffalo.Resource
+	webhooksService *service.WebhooksService
+}
+
+func NewWebhookResource(service *service.WebhooksService) *WebhooksResource {
+	return &WebhooksResource{
+		webhooksService: service,
+	}
+}
+
+// List gets all WebhookSubscriptions. This function is mapped to the path
+// GET /webhooks
+func (v WebhooksResource) List(c buffalo.Context) error {
+
+	webhooks, q, err := v.webhooksService.List(c)
+	if err != nil {
+		return err
+	}
+
+	// Add the paginator to the context so it can be used in the template.
+	c.Set("pagination", q.Paginator)
+
+	c.Set("webhooks", webhooks)
+	return c.Render(http.StatusOK, r.HTML("/webhooks/index.plush.html"))
+}
+
+// Show gets one WebhookSubscription with its delivery log. This function
+// is mapped to the path GET /webhooks/{webhook_id}
+func (v WebhooksResource) Show(c buffalo.Context) error {
+
+	webhook, err := v.webhooksService.Show(c)
+	if err != nil {
+		return err
+	}
+
+	deliveries, q, err := v.webhooksService.Deliveries(c)
+	if err != nil {
+		return err
+	}
+
+	c.Set("pagination", q.Paginator)
+	c.Set("webhook", webhook)
+	c.Set("deliveries", deliveries)
+	return c.Render(http.StatusOK, r.HTML("/webhooks/show.plush.html"))
+}
+
+// New renders the form for creating a new WebhookSubscription.
+// This function is mapped to the path GET /webhooks/new
+func (v WebhooksResource) New(c buffalo.Context) error {
+	return v.form(c, http.StatusOK, "new", &models.WebhookSubscription{Active: true}, nil)
+}
+
+// Create adds a WebhookSubscription to the DB. This function is mapped to
+// the path POST /webhooks
+func (v WebhooksResource) Create(c buffalo.Context) error {
+
+	verrs, webhook, err := v.webhooksService.Create(c)
+	if err != nil {
+		return err
+	}
+
+	if verrs.HasAny() {
+		// Render again the new.html template that the user can
+		// correct the input.
+		return v.form(c, http.StatusUnprocessableEntity, "new", webhook, verrs)
+	}
+
+	// If there are no errors set a success message
+	c.Flash().Add("success", T.Translate(c, "webhook.created.success"))
+
+	// and redirect to the show page, which has the secret
+	return c.Redirect(http.StatusSeeOther, "/webhooks/%v", webhook.ID)
+}
+
+// Edit renders a edit form for a WebhookSubscription. This function is
+// mapped to the path GET /webhooks/{webhook_id}/edit
+func (v WebhooksResource) Edit(c buffalo.Context) error {
+
+	webhook, err := v.webhooksService.Show(c)
+	if err != nil {
+		return err
+	}
+
+	return v.form(c, http.StatusOK, "edit", webhook, nil)
+}
+
+// Update changes a WebhookSubscription in the DB. This function is mapped
+// to the path PUT /webhooks/{webhook_id}
+func (v WebhooksResource) Update(c buffalo.Context) error {
+
+	verrs, webhook, err := v.webhooksService.Update(c)
+	if err != nil {
+		return err
+	}
+
+	if verrs.HasAny() {
+		return v.form(c, http.StatusUnprocessableEntity, "edit", webhook, verrs)
+	}
+
+	c.Flash().Add("success", T.Translate(c, "webhook.updated.success"))
+	return c.Redirect(http.StatusSeeOther, "/webhooks/%v", webhook.ID)
+}
+
+// Destroy deletes a WebhookSubscription and its delivery log from the DB.
+// This function is mapped to the path DELETE /webhooks/{webhook_id}
+func (v WebhooksResource) Destroy(c buffalo.Context) error {
+
+	if _, err := v.webhooksService.Destroy(c); err != nil {
+		return err
+	}
+
+	c.Flash().Add("success", T.Translate(c, "webhook.destroyed.success"))
+	return c.Redirect(http.StatusSeeOther, "/webhooks")
+}
+
+// Resend queues a delivery of the log again. This function is mapped to
+// the path POST /webhooks/{webhook_id}/deliveries/{delivery_id}/resend
+func (v WebhooksResource) Resend(c buffalo.Context) error {
+
+	if _, err := v.webhooksService.Resend(c); err != nil {
+		return err
+	}
+
+	c.Flash().Add("success", T.Translate(c, "webhook.resent.success"))
+	return c.Redirect(http.StatusSeeOther, "/webhooks/%v", c.Param("webhook_id"))
+}
+
+// form renders the new or edit page of a WebhookSubscription.
+func (v WebhooksResource) form(c buffalo.Context, status int, page string, webhook *models.WebhookSubscription, verrs *validate.Errors) error {
+	if verrs != nil {
+		// Make the errors available inside the html template
+		c.Set("errors", verrs)
+	}
+	c.Set("webhook", webhook)
+	c.Set("events", strings.Join(models.WebhookEvents, ", "))
+	return c.Render(status, r.HTML("/webhooks/"+page+".plush.html"))
+}
@@ -0,0 +1,50 @@
+package actions
+
+import (
+	"location_service_v1/ls_v2/models"
+)
+
+func (as *ActionSuite) Test_WebhooksResource_Create() {
+	res := as.HTML("/webhooks").Post(map[string]string{"URL": "ftp://partner.example.com", "Events": "point.closed"})
+	as.Equal(422, res.Code)
+	as.Contains(res.Body.String(), "URL must be an http or https URL.")
+	as.Contains(res.Body.String(), `&#34;point.closed&#34; is not an event`)
+
+	res = as.HTML("/webhooks").Post(map[string]string{"URL": "https://partner.example.com/hooks", "Events": "point.created, point.deleted", "Active": "true"})
+	as.Equal(303, res.Code)
+
+	webhook := &models.WebhookSubscription{}
+	as.NoError(as.DB.First(webhook))
+	as.Equal([]string{"point.created", "point.deleted"}, []string(webhook.Events))
+	as.True(webhook.Active)
+	as.Contains(webhook.Secret, "whsec_")
+}
+
+func (as *ActionSuite) Test_Webhooks_Fire() {
+	webhook := &models.WebhookSubscription{URL: "https://partner.example.com/hooks", Events: []string{models.EventPointCreated}, Active: true}
+	as.NoError(as.DB.Create(webhook))
+	inactive := &models.WebhookSubscription{URL: "https://old.example.com/hooks", Active: false}
+	as.NoError(as.DB.Create(inactive))
+
+	res := as.HTML("/points").Post(map[string]string{"Name": "Tverskaya 7"})
+	as.Equal(303, res.Code)
+
+	// a rejected point fires nothing
+	res = as.HTML("/points").Post(map[string]string{"Name": ""})
+	as.Equal(422, res.Code)
+
+	deliveries := models.WebhookDeliveries{}
+	as.NoError(as.DB.All(&deliveries))
+	as.Len(deliveries, 1)
+	as.Equal(webhook.ID, deliveries[0].SubscriptionID)
+	as.Equal(models.EventPointCreated, deliveries[0].Event)
+	as.Equal(models.DeliveryPending, deliveries[0].Status)
+	as.Contains(deliveries[0].Payload, `"name":"Tverskaya 7"`)
+
+	res = as.HTML("/webhooks/%s/deliveries/%s/resend", webhook.ID, deliveries[0].ID).Post(nil)
+	as.Equal(303, res.Code)
+
+	count, err := as.DB.Count(&models.WebhookDeliveries{})
+	as.NoError(err)
+	as.Equal(2, count)
+}
@@ -0,0 +1,55 @@
+package dto
+
+import (
+	"location_service_v1/ls_v2/models"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// Company is a company as returned by the API.
+type Company struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Points    []Point   `json:"points,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewCompany is the API form of c.
+func NewCompany(c models.Company) Company {
+	company := Company{
+		ID:        c.ID,
+		Name:      c.Name,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	}
+	if len(c.Points) > 0 {
+		company.Points = NewPoints(c.Points)
+	}
+	return company
+}
+
+// NewCompanies is the API form of companies.
+func NewCompanies(companies models.Companies) []Company {
+	list := make([]Company, len(companies))
+	for i, c := range companies {
+		list[i] = NewCompany(c)
+	}
+	return list
+}
+
+// CompanyInput is the body of the create and update requests of a company.
+type CompanyInput struct {
+	Name string `json:"name"`
+}
+
+// NewCompanyInput is the input that would leave c as it is.
+func NewCompanyInput(c models.Company) CompanyInput {
+	return CompanyInput{Name: c.Name}
+}
+
+// Apply copies the input to c.
+func (in CompanyInput) Apply(c *models.Company) {
+	c.Name = in.Name
+}
@@ -0,0 +1,68 @@
+// Package dto holds the request and response bodies of the versioned JSON
+// API. Unlike the models they do not change with the database schema, so
+// renaming a column or a model field must not change them.
+package dto
+
+import (
+	"encoding/xml"
+	"sort"
+)
+
+// Error is the body of every failed JSON and XML response, of the API and
+// of the HTML resources alike:
+//
+//	{"error": {"status": 422, "code": "validation_failed", "message": "...", "fields": {"name": ["..."]}}}
+//
+// In XML the detail is the <error> root element.
+type Error struct {
+	Error ErrorDetail `json:"error"`
+}
+
+// ErrorDetail describes what went wrong. Code is one of the models.ErrorCode
+// values or "internal_error", Fields lists the validation messages of each
+// invalid input field.
+type ErrorDetail struct {
+	Status  int         `json:"status" xml:"status"`
+	Code    string      `json:"code" xml:"code"`
+	Message string      `json:"message" xml:"message"`
+	Fields  FieldErrors `json:"fields,omitempty" xml:"fields,omitempty"`
+}
+
+// NewError is the body of a failed response.
+func NewError(status int, code string, message string) Error {
+	return Error{Error: ErrorDetail{Status: status, Code: code, Message: message}}
+}
+
+// MarshalXML writes the detail as the <error> element.
+func (e Error) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	return enc.EncodeElement(e.Error, xml.StartElement{Name: xml.Name{Local: "error"}})
+}
+
+// FieldErrors are the validation messages of each field.
+type FieldErrors map[string][]string
+
+// MarshalXML writes one <field name="..."> element per message, in the
+// order of the field names.
+func (f FieldErrors) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	names := make([]string, 0, len(f))
+	for name := range f {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	for _, name := range names {
+		for _, message := range f[name] {
+			field := xml.StartElement{
+				Name: xml.Name{Local: "field"},
+				Attr: []xml.Attr{{Name: xml.Name{Local: "name"}, Value: name}},
+			}
+			if err := enc.EncodeElement(message, field); err != nil {
+				return err
+			}
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
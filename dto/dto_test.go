@@ -0,0 +1,36 @@
+package dto
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+)
+
+func Test_Error_Marshal(t *testing.T) {
+	body := NewError(422, "validation_failed", "validation failed")
+	body.Error.Fields = FieldErrors{"name": {"Name can not be blank."}}
+
+	b, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"error":{"status":422,"code":"validation_failed","message":"validation failed","fields":{"name":["Name can not be blank."]}}}`
+	if string(b) != want {
+		t.Errorf("got %s", b)
+	}
+
+	b, err = xml.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = `<error><status>422</status><code>validation_failed</code><message>validation failed</message><fields><field name="name">Name can not be blank.</field></fields></error>`
+	if string(b) != want {
+		t.Errorf("got %s", b)
+	}
+
+	b, _ = xml.Marshal(NewError(404, "not_found", "point not found"))
+	want = `<error><status>404</status><code>not_found</code><message>point not found</message></error>`
+	if string(b) != want {
+		t.Errorf("got %s", b)
+	}
+}
@@ -0,0 +1,132 @@
+package dto
+
+import (
+	"location_service_v1/ls_v2/models"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// Point is a pick-up point as returned by the API.
+type Point struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	PointID     int       `json:"point_id"`
+	Address     string    `json:"address"`
+	City        string    `json:"city"`
+	Description string    `json:"description"`
+	OwnerID     int       `json:"owner_id"`
+	OwnerName   string    `json:"owner_name"`
+	MaxLength   int       `json:"max_length"`
+	MaxWidth    int       `json:"max_width"`
+	MaxHeight   int       `json:"max_height"`
+	MaxWeight   int       `json:"max_weight"`
+	CompanyID   uuid.UUID `json:"company_id"`
+	Company     *Company  `json:"company,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// NewPoint is the API form of p.
+func NewPoint(p models.Point) Point {
+	point := Point{
+		ID:          p.ID,
+		Name:        p.Name,
+		PointID:     p.PointID,
+		Address:     p.Address,
+		City:        p.CityName,
+		Description: p.OutDescription,
+		OwnerID:     p.OwnerID,
+		OwnerName:   p.OwnerName,
+		MaxLength:   p.MaxLength,
+		MaxWidth:    p.MaxWidth,
+		MaxHeight:   p.MaxHeight,
+		MaxWeight:   p.MaxWeight,
+		CompanyID:   p.CompanyID,
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+	}
+	if p.Company != nil {
+		company := NewCompany(*p.Company)
+		point.Company = &company
+	}
+	return point
+}
+
+// NewPoints is the API form of points.
+func NewPoints(points models.Points) []Point {
+	list := make([]Point, len(points))
+	for i, p := range points {
+		list[i] = NewPoint(p)
+	}
+	return list
+}
+
+// PointChangeFeed is the API form of a page of the change feed.
+type PointChangeFeed struct {
+	Upserts   []Point                `json:"upserts"`
+	Deletions []models.PointDeletion `json:"deletions"`
+	Next      string                 `json:"next"`
+	HasMore   bool                   `json:"has_more"`
+}
+
+// NewPointChangeFeed is the API form of feed.
+func NewPointChangeFeed(feed models.PointChangeFeed) PointChangeFeed {
+	return PointChangeFeed{
+		Upserts:   NewPoints(feed.Upserts),
+		Deletions: feed.Deletions,
+		Next:      feed.Next,
+		HasMore:   feed.HasMore,
+	}
+}
+
+// PointInput is the body of the create and update requests of a point.
+type PointInput struct {
+	Name        string    `json:"name"`
+	PointID     int       `json:"point_id"`
+	Address     string    `json:"address"`
+	City        string    `json:"city"`
+	Description string    `json:"description"`
+	OwnerID     int       `json:"owner_id"`
+	OwnerName   string    `json:"owner_name"`
+	MaxLength   int       `json:"max_length"`
+	MaxWidth    int       `json:"max_width"`
+	MaxHeight   int       `json:"max_height"`
+	MaxWeight   int       `json:"max_weight"`
+	CompanyID   uuid.UUID `json:"company_id"`
+}
+
+// NewPointInput is the input that would leave p as it is, e.g. the
+// document a PATCH request applies to.
+func NewPointInput(p models.Point) PointInput {
+	return PointInput{
+		Name:        p.Name,
+		PointID:     p.PointID,
+		Address:     p.Address,
+		City:        p.CityName,
+		Description: p.OutDescription,
+		OwnerID:     p.OwnerID,
+		OwnerName:   p.OwnerName,
+		MaxLength:   p.MaxLength,
+		MaxWidth:    p.MaxWidth,
+		MaxHeight:   p.MaxHeight,
+		MaxWeight:   p.MaxWeight,
+		CompanyID:   p.CompanyID,
+	}
+}
+
+// Apply copies the input to p.
+func (in PointInput) Apply(p *models.Point) {
+	p.Name = in.Name
+	p.PointID = in.PointID
+	p.Address = in.Address
+	p.CityName = in.City
+	p.OutDescription = in.Description
+	p.OwnerID = in.OwnerID
+	p.OwnerName = in.OwnerName
+	p.MaxLength = in.MaxLength
+	p.MaxWidth = in.MaxWidth
+	p.MaxHeight = in.MaxHeight
+	p.MaxWeight = in.MaxWeight
+	p.CompanyID = in.CompanyID
+}
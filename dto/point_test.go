@@ -0,0 +1,46 @@
+package dto
+
+import (
+	"encoding/json"
+	"location_service_v1/ls_v2/models"
+	"strings"
+	"testing"
+)
+
+func Test_PointInput_Apply(t *testing.T) {
+	in := PointInput{Name: "Tverskaya 7", City: "Moscow", Description: "2nd floor", OwnerID: 5}
+	p := &models.Point{}
+	in.Apply(p)
+
+	if p.CityName != "Moscow" || p.OutDescription != "2nd floor" || p.OwnerID != 5 {
+		t.Errorf("got %+v", p)
+	}
+	if got := NewPoint(*p); got.City != in.City || got.Description != in.Description {
+		t.Errorf("got %+v", got)
+	}
+}
+
+func Test_Point_JSON(t *testing.T) {
+	b, err := json.Marshal(NewPoint(models.Point{CityName: "Moscow", OwnerID: 5}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{`"city":"Moscow"`, `"owner_id":5`} {
+		if !strings.Contains(string(b), key) {
+			t.Errorf("%s is missing from %s", key, b)
+		}
+	}
+	if strings.Contains(string(b), "citiName") {
+		t.Errorf("model keys leak into %s", b)
+	}
+}
+
+func Test_NewPointInput(t *testing.T) {
+	in := PointInput{Name: "Tverskaya 7", City: "Moscow", OwnerID: 5, MaxWeight: 5000}
+	p := &models.Point{}
+	in.Apply(p)
+
+	if got := NewPointInput(*p); got != in {
+		t.Errorf("got %+v, want %+v", got, in)
+	}
+}
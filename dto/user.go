@@ -0,0 +1,50 @@
+package dto
+
+import (
+	"location_service_v1/ls_v2/models"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// User is a user as returned by the API.
+type User struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewUser is the API form of u.
+func NewUser(u models.User) User {
+	return User{
+		ID:        u.ID,
+		Name:      u.Name,
+		CreatedAt: u.CreatedAt,
+		UpdatedAt: u.UpdatedAt,
+	}
+}
+
+// NewUsers is the API form of users.
+func NewUsers(users models.Users) []User {
+	list := make([]User, len(users))
+	for i, u := range users {
+		list[i] = NewUser(u)
+	}
+	return list
+}
+
+// UserInput is the body of the create and update requests of a user.
+type UserInput struct {
+	Name string `json:"name"`
+}
+
+// NewUserInput is the input that would leave u as it is.
+func NewUserInput(u models.User) UserInput {
+	return UserInput{Name: u.Name}
+}
+
+// Apply copies the input to u.
+func (in UserInput) Apply(u *models.User) {
+	u.Name = in.Name
+}
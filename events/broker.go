@@ -0,0 +1,165 @@
+// Package events fans the live events out to the open /events streams.
+// Changes publish them with PostgreSQL NOTIFY; the Broker of every
+// instance LISTENs for them, so a stream gets the changes made through
+// any instance of the app.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"location_service_v1/ls_v2/models"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gofrs/uuid"
+	"github.com/lib/pq"
+)
+
+// Tuning of the Broker.
+const (
+	subscriptionBuffer = 64
+	pingInterval       = 90 * time.Second
+)
+
+// Filter picks the point events of a stream. The zero Filter lets every
+// event through; import events always go through.
+type Filter struct {
+	CompanyID uuid.UUID
+	City      string
+}
+
+// ParseFilter reads the "company_id" and "city" parameters of a stream.
+func ParseFilter(companyID, city string) (Filter, error) {
+	f := Filter{City: strings.TrimSpace(city)}
+	if companyID != "" {
+		id, err := uuid.FromString(companyID)
+		if err != nil {
+			return Filter{}, fmt.Errorf("company_id must be a UUID")
+		}
+		f.CompanyID = id
+	}
+	return f, nil
+}
+
+// Match reports whether the event goes to a stream with the filter.
+func (f Filter) Match(e models.LiveEvent) bool {
+	if e.Type == models.EventImportProgress {
+		return true
+	}
+	if f.CompanyID != uuid.Nil && e.CompanyID != f.CompanyID {
+		return false
+	}
+	if f.City != "" && !strings.EqualFold(e.City, f.City) {
+		return false
+	}
+	return true
+}
+
+// Subscription is an open stream. Events is closed when the stream falls
+// too far behind, and the client is expected to reconnect.
+type Subscription struct {
+	Events <-chan models.LiveEvent
+	events chan models.LiveEvent
+	filter Filter
+}
+
+// Broker sends the events it gets to the matching subscriptions.
+type Broker struct {
+	url    string
+	logger buffalo.Logger
+
+	mu   sync.Mutex
+	subs map[*Subscription]bool
+}
+
+// NewBroker is a. url is the database the events are LISTENed for on.
+func NewBroker(url string, logger buffalo.Logger) *Broker {
+	return &Broker{
+		url:    url,
+		logger: logger,
+		subs:   map[*Subscription]bool{},
+	}
+}
+
+// Subscribe opens a stream of the events that match the filter.
+func (b *Broker) Subscribe(f Filter) *Subscription {
+	ch := make(chan models.LiveEvent, subscriptionBuffer)
+	s := &Subscription{Events: ch, events: ch, filter: f}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subs[s] = true
+	return s
+}
+
+// Unsubscribe closes a stream. It may be called more than once.
+func (b *Broker) Unsubscribe(s *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.drop(s)
+}
+
+// drop closes s. b.mu must be held.
+func (b *Broker) drop(s *Subscription) {
+	if b.subs[s] {
+		delete(b.subs, s)
+		close(s.events)
+	}
+}
+
+// Publish sends the event to the matching streams of this instance. A
+// stream whose buffer is full is closed rather than blocking the others.
+func (b *Broker) Publish(e models.LiveEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for s := range b.subs {
+		if !s.filter.Match(e) {
+			continue
+		}
+		select {
+		case s.events <- e:
+		default:
+			b.drop(s)
+		}
+	}
+}
+
+// Run LISTENs for the events of all instances and publishes them until ctx
+// is done. The listener reconnects by itself; the events sent while it is
+// disconnected are lost.
+func (b *Broker) Run(ctx context.Context) {
+	l := pq.NewListener(b.url, time.Second, time.Minute, func(_ pq.ListenerEventType, err error) {
+		if err != nil {
+			b.logger.Error(err)
+		}
+	})
+	defer l.Close()
+
+	if err := l.Listen(models.LiveEventsChannel); err != nil {
+		b.logger.Error(err)
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case n := <-l.Notify:
+			// nil after a reconnection
+			if n == nil {
+				continue
+			}
+			e := models.LiveEvent{}
+			if err := json.Unmarshal([]byte(n.Extra), &e); err != nil {
+				b.logger.Error(err)
+				continue
+			}
+			b.Publish(e)
+		case <-time.After(pingInterval):
+			go l.Ping()
+		}
+	}
+}
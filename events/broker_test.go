@@ -0,0 +1,66 @@
+package events
+
+import (
+	"testing"
+
+	"location_service_v1/ls_v2/models"
+
+	"github.com/gobuffalo/logger"
+	"github.com/gofrs/uuid"
+)
+
+func Test_Filter_Match(t *testing.T) {
+	company := uuid.Must(uuid.NewV4())
+	f, err := ParseFilter(company.String(), "moscow")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		event models.LiveEvent
+		match bool
+	}{
+		{models.LiveEvent{Type: models.EventPointCreated, CompanyID: company, City: "Moscow"}, true},
+		{models.LiveEvent{Type: models.EventPointCreated, CompanyID: company, City: "Tver"}, false},
+		{models.LiveEvent{Type: models.EventPointCreated, City: "Moscow"}, false},
+		{models.LiveEvent{Type: models.EventImportProgress}, true},
+	}
+	for _, tt := range tests {
+		if got := f.Match(tt.event); got != tt.match {
+			t.Errorf("%+v: got %v, want %v", tt.event, got, tt.match)
+		}
+	}
+
+	if _, err := ParseFilter("42", ""); err == nil {
+		t.Error("expected an error for a broken company_id")
+	}
+}
+
+func Test_Broker_Publish(t *testing.T) {
+	b := NewBroker("", logger.New(logger.ErrorLevel))
+	tver := b.Subscribe(Filter{City: "Tver"})
+	all := b.Subscribe(Filter{})
+
+	b.Publish(models.LiveEvent{Type: models.EventPointUpdated, City: "Moscow"})
+	if len(tver.Events) != 0 || len(all.Events) != 1 {
+		t.Fatalf("got %d and %d events, want 0 and 1", len(tver.Events), len(all.Events))
+	}
+
+	// a stream that does not keep up is closed
+	for i := 0; i < subscriptionBuffer; i++ {
+		b.Publish(models.LiveEvent{Type: models.EventPointUpdated})
+	}
+	n := 0
+	for range all.Events {
+		n++
+	}
+	if n != subscriptionBuffer {
+		t.Errorf("got %d events before the close, want %d", n, subscriptionBuffer)
+	}
+
+	b.Unsubscribe(all)
+	b.Unsubscribe(tver)
+	if _, ok := <-tver.Events; ok {
+		t.Error("expected the stream to be closed")
+	}
+}
@@ -0,0 +1,177 @@
+package gql
+
+import (
+	"context"
+	"location_service_v1/ls_v2/models"
+	"sync"
+
+	"github.com/gofrs/uuid"
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// companyBatch is the companies of one list. The points of all of them are
+// loaded with one query the first time a company of the list asks for its
+// points.
+type companyBatch struct {
+	resolver  *Resolver
+	companies []models.Company
+
+	once   sync.Once
+	points *pointBatch
+	index  map[uuid.UUID][]*pointResolver
+	err    error
+}
+
+func (r *Resolver) newCompanyBatch(companies []models.Company) *companyBatch {
+	return &companyBatch{resolver: r, companies: companies}
+}
+
+// all resolves every company of the batch.
+func (b *companyBatch) all() []*companyResolver {
+	list := make([]*companyResolver, len(b.companies))
+	for i := range b.companies {
+		list[i] = &companyResolver{batch: b, company: &b.companies[i]}
+	}
+	return list
+}
+
+// find resolves the company with the id, nil when it is not in the batch.
+func (b *companyBatch) find(id uuid.UUID) *companyResolver {
+	for i := range b.companies {
+		if b.companies[i].ID == id {
+			return &companyResolver{batch: b, company: &b.companies[i]}
+		}
+	}
+	return nil
+}
+
+// loadPoints loads the points of the batch once. The points of all the
+// companies form one batch whose companies are already known.
+func (b *companyBatch) loadPoints(ctx context.Context) (map[uuid.UUID][]*pointResolver, error) {
+	b.once.Do(func() {
+		if b.err = b.resolver.companiesService.LoadPoints(requestContext(ctx), b.companies); b.err != nil {
+			return
+		}
+
+		points := []models.Point{}
+		for _, company := range b.companies {
+			points = append(points, company.Points...)
+		}
+		b.points = b.resolver.newPointBatch(points)
+		b.points.once.Do(func() { b.points.companies = b })
+
+		b.index = map[uuid.UUID][]*pointResolver{}
+		for _, point := range b.points.all() {
+			b.index[point.point.CompanyID] = append(b.index[point.point.CompanyID], point)
+		}
+	})
+	return b.index, b.err
+}
+
+// companyResolver resolves the fields of a Company.
+type companyResolver struct {
+	batch   *companyBatch
+	company *models.Company
+}
+
+func (r *companyResolver) ID() graphql.ID          { return graphql.ID(r.company.ID.String()) }
+func (r *companyResolver) Name() string            { return r.company.Name }
+func (r *companyResolver) CreatedAt() graphql.Time { return graphql.Time{Time: r.company.CreatedAt} }
+func (r *companyResolver) UpdatedAt() graphql.Time { return graphql.Time{Time: r.company.UpdatedAt} }
+
+// Points resolves the points of the company, batched with the other
+// companies of the list.
+func (r *companyResolver) Points(ctx context.Context) ([]*pointResolver, error) {
+	index, err := r.batch.loadPoints(ctx)
+	if err != nil {
+		return nil, resolveErr(ctx, err)
+	}
+	points := index[r.company.ID]
+	if points == nil {
+		points = []*pointResolver{}
+	}
+	return points, nil
+}
+
+// companyPageResolver resolves a CompanyPage.
+type companyPageResolver struct {
+	cursors
+	batch *companyBatch
+}
+
+func (r *companyPageResolver) Nodes() []*companyResolver {
+	return r.batch.all()
+}
+
+// Companies lists the companies, optionally by name.
+func (r *Resolver) Companies(ctx context.Context, args struct {
+	Name *string
+	pageArgs
+}) (*companyPageResolver, error) {
+	params := args.params()
+	set(params, "name", args.Name)
+
+	companies, page, err := r.companiesService.Scroll(withParams(ctx, params))
+	if err != nil {
+		return nil, resolveErr(ctx, err)
+	}
+	return &companyPageResolver{cursors: cursors{page}, batch: r.newCompanyBatch(*companies)}, nil
+}
+
+// Company gets one company.
+func (r *Resolver) Company(ctx context.Context, args struct{ ID graphql.ID }) (*companyResolver, error) {
+	company, err := r.companiesService.Show(withParams(ctx, idParams("company_id", args.ID)))
+	if err != nil {
+		return nil, resolveErr(ctx, err)
+	}
+	return r.newCompanyBatch([]models.Company{*company}).all()[0], nil
+}
+
+// companyInput is the CompanyInput input.
+type companyInput struct {
+	Name string
+}
+
+// CreateCompany adds a company.
+func (r *Resolver) CreateCompany(ctx context.Context, args struct{ Input companyInput }) (*companyResolver, error) {
+	company := &models.Company{Name: args.Input.Name}
+
+	verrs, err := r.companiesService.Insert(requestContext(ctx), company)
+	if err == nil && verrs.HasAny() {
+		err = models.ValidationError(verrs)
+	}
+	if err != nil {
+		return nil, resolveErr(ctx, err)
+	}
+	return r.newCompanyBatch([]models.Company{*company}).all()[0], nil
+}
+
+// UpdateCompany renames a company.
+func (r *Resolver) UpdateCompany(ctx context.Context, args struct {
+	ID    graphql.ID
+	Input companyInput
+}) (*companyResolver, error) {
+	company, err := r.companiesService.Edit(withParams(ctx, idParams("company_id", args.ID)))
+	if err != nil {
+		return nil, resolveErr(ctx, err)
+	}
+	company.Name = args.Input.Name
+
+	verrs, err := r.companiesService.Save(requestContext(ctx), company)
+	if err == nil && verrs.HasAny() {
+		err = models.ValidationError(verrs)
+	}
+	if err != nil {
+		return nil, resolveErr(ctx, err)
+	}
+	return r.newCompanyBatch([]models.Company{*company}).all()[0], nil
+}
+
+// DeleteCompany deletes a company.
+func (r *Resolver) DeleteCompany(ctx context.Context, args struct{ ID graphql.ID }) (*companyResolver, error) {
+	company, err := r.companiesService.Destroy(withParams(ctx, idParams("company_id", args.ID)))
+	if err != nil {
+		return nil, resolveErr(ctx, err)
+	}
+	return r.newCompanyBatch([]models.Company{*company}).all()[0], nil
+}
@@ -0,0 +1,88 @@
+package gql
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+
+	"github.com/gobuffalo/buffalo"
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+type contextKey struct{}
+
+// WithContext stores the buffalo.Context of the request in ctx, for the
+// resolvers to reach the transaction and the services.
+func WithContext(ctx context.Context, c buffalo.Context) context.Context {
+	return context.WithValue(ctx, contextKey{}, c)
+}
+
+// paramsContext is the request context with the parameters replaced by the
+// arguments of a field, so that the services read them as they read query
+// parameters.
+type paramsContext struct {
+	buffalo.Context
+	params url.Values
+}
+
+// Params returns the field arguments.
+func (c paramsContext) Params() buffalo.ParamValues {
+	return c.params
+}
+
+// Param returns one field argument.
+func (c paramsContext) Param(key string) string {
+	return c.params.Get(key)
+}
+
+// requestContext is the buffalo.Context stored in ctx by WithContext.
+func requestContext(ctx context.Context) buffalo.Context {
+	return ctx.Value(contextKey{}).(buffalo.Context)
+}
+
+// withParams is the buffalo.Context of ctx with params as its parameters.
+func withParams(ctx context.Context, params url.Values) buffalo.Context {
+	return paramsContext{Context: requestContext(ctx), params: params}
+}
+
+// set adds the value to params unless it is nil.
+func set(params url.Values, key string, value *string) {
+	if value != nil {
+		params.Set(key, *value)
+	}
+}
+
+// setInt adds the value to params unless it is nil.
+func setInt(params url.Values, key string, value *int32) {
+	if value != nil {
+		params.Set(key, strconv.Itoa(int(*value)))
+	}
+}
+
+// setID adds the value to params unless it is nil.
+func setID(params url.Values, key string, value *graphql.ID) {
+	if value != nil {
+		params.Set(key, string(*value))
+	}
+}
+
+// pageArgs are the keyset pagination arguments of the lists.
+type pageArgs struct {
+	Limit  *int32
+	After  *string
+	Before *string
+}
+
+// params are the query parameters of the page.
+func (a pageArgs) params() url.Values {
+	params := url.Values{}
+	setInt(params, "limit", a.Limit)
+	set(params, "after", a.After)
+	set(params, "before", a.Before)
+	return params
+}
+
+// idParams are the parameters of a path such as /points/{point_id}.
+func idParams(key string, id graphql.ID) url.Values {
+	return url.Values{key: {string(id)}}
+}
@@ -0,0 +1,40 @@
+package gql
+
+import (
+	"context"
+	"errors"
+	"location_service_v1/ls_v2/models"
+
+	"github.com/gobuffalo/buffalo"
+)
+
+// resolverError is a models.Error in the "errors" of a GraphQL response,
+// with its code and field details in the extensions.
+type resolverError struct {
+	err *models.Error
+}
+
+func (e resolverError) Error() string {
+	return e.err.Message
+}
+
+// Extensions implements the extensions of graphql-go.
+func (e resolverError) Extensions() map[string]interface{} {
+	ext := map[string]interface{}{"code": e.err.Code}
+	if len(e.err.Fields) > 0 {
+		ext["fields"] = e.err.Fields
+	}
+	return ext
+}
+
+// resolveErr keeps the message and code of models.Error. Other errors are
+// logged and reported without details, like the internal errors of the
+// HTTP handlers.
+func resolveErr(ctx context.Context, err error) error {
+	var e *models.Error
+	if errors.As(err, &e) {
+		return resolverError{err: e}
+	}
+	ctx.Value(contextKey{}).(buffalo.Context).Logger().Error(err)
+	return errors.New("internal error")
+}
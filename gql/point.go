@@ -0,0 +1,248 @@
+package gql
+
+import (
+	"context"
+	"location_service_v1/ls_v2/models"
+	"sync"
+
+	"github.com/gofrs/uuid"
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// pointBatch is the points of one list. The companies of all of them are
+// loaded with one query the first time a point of the list asks for its
+// company.
+type pointBatch struct {
+	resolver *Resolver
+	points   []models.Point
+
+	once      sync.Once
+	companies *companyBatch
+	err       error
+}
+
+func (r *Resolver) newPointBatch(points []models.Point) *pointBatch {
+	return &pointBatch{resolver: r, points: points}
+}
+
+// all resolves every point of the batch.
+func (b *pointBatch) all() []*pointResolver {
+	list := make([]*pointResolver, len(b.points))
+	for i := range b.points {
+		list[i] = &pointResolver{batch: b, point: &b.points[i]}
+	}
+	return list
+}
+
+// loadCompanies loads the companies of the batch once.
+func (b *pointBatch) loadCompanies(ctx context.Context) (*companyBatch, error) {
+	b.once.Do(func() {
+		if b.err = b.resolver.pointsService.LoadCompanies(requestContext(ctx), b.points); b.err != nil {
+			return
+		}
+
+		companies := []models.Company{}
+		seen := map[uuid.UUID]bool{}
+		for _, point := range b.points {
+			if point.Company != nil && !seen[point.CompanyID] {
+				seen[point.CompanyID] = true
+				companies = append(companies, *point.Company)
+			}
+		}
+		b.companies = b.resolver.newCompanyBatch(companies)
+	})
+	return b.companies, b.err
+}
+
+// pointResolver resolves the fields of a Point.
+type pointResolver struct {
+	batch *pointBatch
+	point *models.Point
+}
+
+func (r *pointResolver) ID() graphql.ID          { return graphql.ID(r.point.ID.String()) }
+func (r *pointResolver) Name() string            { return r.point.Name }
+func (r *pointResolver) PointID() int32          { return int32(r.point.PointID) }
+func (r *pointResolver) Address() string         { return r.point.Address }
+func (r *pointResolver) City() string            { return r.point.CityName }
+func (r *pointResolver) Description() string     { return r.point.OutDescription }
+func (r *pointResolver) OwnerID() int32          { return int32(r.point.OwnerID) }
+func (r *pointResolver) OwnerName() string       { return r.point.OwnerName }
+func (r *pointResolver) MaxLength() int32        { return int32(r.point.MaxLength) }
+func (r *pointResolver) MaxWidth() int32         { return int32(r.point.MaxWidth) }
+func (r *pointResolver) MaxHeight() int32        { return int32(r.point.MaxHeight) }
+func (r *pointResolver) MaxWeight() int32        { return int32(r.point.MaxWeight) }
+func (r *pointResolver) CompanyID() graphql.ID   { return graphql.ID(r.point.CompanyID.String()) }
+func (r *pointResolver) CreatedAt() graphql.Time { return graphql.Time{Time: r.point.CreatedAt} }
+func (r *pointResolver) UpdatedAt() graphql.Time { return graphql.Time{Time: r.point.UpdatedAt} }
+
+// Company resolves the company of the point, batched with the other points
+// of the list.
+func (r *pointResolver) Company(ctx context.Context) (*companyResolver, error) {
+	companies, err := r.batch.loadCompanies(ctx)
+	if err != nil {
+		return nil, resolveErr(ctx, err)
+	}
+	return companies.find(r.point.CompanyID), nil
+}
+
+// pointPageResolver resolves a PointPage.
+type pointPageResolver struct {
+	cursors
+	batch *pointBatch
+}
+
+func (r *pointPageResolver) Nodes() []*pointResolver {
+	return r.batch.all()
+}
+
+// pointFilter is the PointFilter input.
+type pointFilter struct {
+	CompanyID    *graphql.ID
+	City         *string
+	OwnerID      *int32
+	Name         *string
+	CreatedAfter *string
+	Problem      *string
+	Parcel       *string
+	WeightG      *int32
+}
+
+// Points lists the points matching the filter.
+func (r *Resolver) Points(ctx context.Context, args struct {
+	Filter *pointFilter
+	pageArgs
+}) (*pointPageResolver, error) {
+	params := args.params()
+	if f := args.Filter; f != nil {
+		setID(params, "company_id", f.CompanyID)
+		set(params, "city", f.City)
+		setInt(params, "owner_id", f.OwnerID)
+		set(params, "name", f.Name)
+		set(params, "created_after", f.CreatedAfter)
+		set(params, "problem", f.Problem)
+		set(params, "parcel", f.Parcel)
+		setInt(params, "weight_g", f.WeightG)
+	}
+
+	points, page, err := r.pointsService.Scroll(withParams(ctx, params))
+	if err != nil {
+		return nil, resolveErr(ctx, err)
+	}
+	return &pointPageResolver{cursors: cursors{page}, batch: r.newPointBatch(*points)}, nil
+}
+
+// Point gets one point.
+func (r *Resolver) Point(ctx context.Context, args struct{ ID graphql.ID }) (*pointResolver, error) {
+	point, err := r.pointsService.Show(withParams(ctx, idParams("point_id", args.ID)))
+	if err != nil {
+		return nil, resolveErr(ctx, err)
+	}
+	return r.newPointBatch([]models.Point{*point}).all()[0], nil
+}
+
+// pointInput is the PointInput input.
+type pointInput struct {
+	Name        *string
+	PointID     *int32
+	Address     *string
+	City        *string
+	Description *string
+	OwnerID     *int32
+	OwnerName   *string
+	MaxLength   *int32
+	MaxWidth    *int32
+	MaxHeight   *int32
+	MaxWeight   *int32
+	CompanyID   *graphql.ID
+}
+
+// apply copies the given fields of the input to p.
+func (in pointInput) apply(p *models.Point) error {
+	if in.CompanyID != nil {
+		id, err := uuid.FromString(string(*in.CompanyID))
+		if err != nil {
+			return models.BadRequestError(err)
+		}
+		p.CompanyID = id
+	}
+	for _, f := range []struct {
+		from *string
+		to   *string
+	}{
+		{in.Name, &p.Name},
+		{in.Address, &p.Address},
+		{in.City, &p.CityName},
+		{in.Description, &p.OutDescription},
+		{in.OwnerName, &p.OwnerName},
+	} {
+		if f.from != nil {
+			*f.to = *f.from
+		}
+	}
+	for _, f := range []struct {
+		from *int32
+		to   *int
+	}{
+		{in.PointID, &p.PointID},
+		{in.OwnerID, &p.OwnerID},
+		{in.MaxLength, &p.MaxLength},
+		{in.MaxWidth, &p.MaxWidth},
+		{in.MaxHeight, &p.MaxHeight},
+		{in.MaxWeight, &p.MaxWeight},
+	} {
+		if f.from != nil {
+			*f.to = int(*f.from)
+		}
+	}
+	return nil
+}
+
+// CreatePoint adds a point.
+func (r *Resolver) CreatePoint(ctx context.Context, args struct{ Input pointInput }) (*pointResolver, error) {
+	point := &models.Point{}
+	if err := args.Input.apply(point); err != nil {
+		return nil, resolveErr(ctx, err)
+	}
+
+	verrs, err := r.pointsService.Insert(requestContext(ctx), point)
+	if err == nil && verrs.HasAny() {
+		err = models.ValidationError(verrs)
+	}
+	if err != nil {
+		return nil, resolveErr(ctx, err)
+	}
+	return r.newPointBatch([]models.Point{*point}).all()[0], nil
+}
+
+// UpdatePoint changes the given fields of a point.
+func (r *Resolver) UpdatePoint(ctx context.Context, args struct {
+	ID    graphql.ID
+	Input pointInput
+}) (*pointResolver, error) {
+	point, err := r.pointsService.Edit(withParams(ctx, idParams("point_id", args.ID)))
+	if err != nil {
+		return nil, resolveErr(ctx, err)
+	}
+	if err := args.Input.apply(point); err != nil {
+		return nil, resolveErr(ctx, err)
+	}
+
+	verrs, err := r.pointsService.Save(requestContext(ctx), point)
+	if err == nil && verrs.HasAny() {
+		err = models.ValidationError(verrs)
+	}
+	if err != nil {
+		return nil, resolveErr(ctx, err)
+	}
+	return r.newPointBatch([]models.Point{*point}).all()[0], nil
+}
+
+// DeletePoint deletes a point.
+func (r *Resolver) DeletePoint(ctx context.Context, args struct{ ID graphql.ID }) (*pointResolver, error) {
+	point, err := r.pointsService.Destroy(withParams(ctx, idParams("point_id", args.ID)))
+	if err != nil {
+		return nil, resolveErr(ctx, err)
+	}
+	return r.newPointBatch([]models.Point{*point}).all()[0], nil
+}
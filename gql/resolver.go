@@ -0,0 +1,52 @@
+// Package gql serves points, companies and users over GraphQL. The
+// resolvers call the same services as the HTTP handlers.
+package gql
+
+import (
+	"location_service_v1/ls_v2/repository"
+	"location_service_v1/ls_v2/service"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// Resolver is the root resolver of the queries and mutations.
+type Resolver struct {
+	pointsService    *service.PointsService
+	companiesService *service.CompaniesService
+	usersService     *service.UsersService
+}
+
+// NewSchema parses the schema with the resolvers of the services.
+func NewSchema(pointsService *service.PointsService, companiesService *service.CompaniesService, usersService *service.UsersService) (*graphql.Schema, error) {
+	resolver := &Resolver{
+		pointsService:    pointsService,
+		companiesService: companiesService,
+		usersService:     usersService,
+	}
+	// All resolvers share the transaction of the request, which cannot run
+	// queries in parallel.
+	return graphql.ParseSchema(schema, resolver, graphql.MaxParallelism(1))
+}
+
+// cursors are the nextCursor and prevCursor fields of the pages.
+type cursors struct {
+	page *repository.CursorPage
+}
+
+// NextCursor is the "after" argument of the next page.
+func (c cursors) NextCursor() *string {
+	return optional(c.page.NextCursor)
+}
+
+// PrevCursor is the "before" argument of the previous page.
+func (c cursors) PrevCursor() *string {
+	return optional(c.page.PrevCursor)
+}
+
+// optional is nil for an empty string.
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,125 @@
+package gql
+
+// schema is the GraphQL schema served at /graphql. Lists are read page by
+// page with the same keyset cursors as the JSON lists: pass the nextCursor
+// of a page as "after" to get the next one.
+const schema = `
+schema {
+	query: Query
+	mutation: Mutation
+}
+
+scalar Time
+
+type Query {
+	points(filter: PointFilter, limit: Int, after: String, before: String): PointPage!
+	point(id: ID!): Point
+	companies(name: String, limit: Int, after: String, before: String): CompanyPage!
+	company(id: ID!): Company
+	users(name: String, limit: Int, after: String, before: String): UserPage!
+	user(id: ID!): User
+}
+
+type Mutation {
+	createPoint(input: PointInput!): Point!
+	updatePoint(id: ID!, input: PointInput!): Point!
+	deletePoint(id: ID!): Point!
+	createCompany(input: CompanyInput!): Company!
+	updateCompany(id: ID!, input: CompanyInput!): Company!
+	deleteCompany(id: ID!): Company!
+	createUser(input: UserInput!): User!
+	updateUser(id: ID!, input: UserInput!): User!
+	deleteUser(id: ID!): User!
+}
+
+# Filters of the points list, as the query parameters of GET /points.
+input PointFilter {
+	companyId: ID
+	city: String
+	ownerId: Int
+	name: String
+	createdAfter: String
+	# no_company, no_address, no_city or duplicate_point_id
+	problem: String
+	# parcel dimensions in mm, e.g. "300x200x100"
+	parcel: String
+	weightG: Int
+}
+
+type Point {
+	id: ID!
+	name: String!
+	pointId: Int!
+	address: String!
+	city: String!
+	description: String!
+	ownerId: Int!
+	ownerName: String!
+	# cell size in mm and weight in g, 0 when unknown
+	maxLength: Int!
+	maxWidth: Int!
+	maxHeight: Int!
+	maxWeight: Int!
+	companyId: ID!
+	company: Company
+	createdAt: Time!
+	updatedAt: Time!
+}
+
+type Company {
+	id: ID!
+	name: String!
+	points: [Point!]!
+	createdAt: Time!
+	updatedAt: Time!
+}
+
+type User {
+	id: ID!
+	name: String!
+	createdAt: Time!
+	updatedAt: Time!
+}
+
+type PointPage {
+	nodes: [Point!]!
+	nextCursor: String
+	prevCursor: String
+}
+
+type CompanyPage {
+	nodes: [Company!]!
+	nextCursor: String
+	prevCursor: String
+}
+
+type UserPage {
+	nodes: [User!]!
+	nextCursor: String
+	prevCursor: String
+}
+
+# Fields left out are not changed by an update and are empty on create.
+input PointInput {
+	name: String
+	pointId: Int
+	address: String
+	city: String
+	description: String
+	ownerId: Int
+	ownerName: String
+	maxLength: Int
+	maxWidth: Int
+	maxHeight: Int
+	maxWeight: Int
+	companyId: ID
+}
+
+input CompanyInput {
+	name: String!
+}
+
+input UserInput {
+	name: String!
+}
+`
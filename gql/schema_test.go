@@ -0,0 +1,12 @@
+package gql
+
+import (
+	"testing"
+)
+
+func Test_NewSchema(t *testing.T) {
+	// ParseSchema checks every field of the schema against the resolvers.
+	if _, err := NewSchema(nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+}
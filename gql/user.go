@@ -0,0 +1,105 @@
+package gql
+
+import (
+	"context"
+	"location_service_v1/ls_v2/models"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// userResolver resolves the fields of a User.
+type userResolver struct {
+	user *models.User
+}
+
+func (r *userResolver) ID() graphql.ID          { return graphql.ID(r.user.ID.String()) }
+func (r *userResolver) Name() string            { return r.user.Name }
+func (r *userResolver) CreatedAt() graphql.Time { return graphql.Time{Time: r.user.CreatedAt} }
+func (r *userResolver) UpdatedAt() graphql.Time { return graphql.Time{Time: r.user.UpdatedAt} }
+
+// userPageResolver resolves a UserPage.
+type userPageResolver struct {
+	cursors
+	users models.Users
+}
+
+func (r *userPageResolver) Nodes() []*userResolver {
+	list := make([]*userResolver, len(r.users))
+	for i := range r.users {
+		list[i] = &userResolver{user: &r.users[i]}
+	}
+	return list
+}
+
+// Users lists the users, optionally by name.
+func (r *Resolver) Users(ctx context.Context, args struct {
+	Name *string
+	pageArgs
+}) (*userPageResolver, error) {
+	params := args.params()
+	set(params, "name", args.Name)
+
+	users, page, err := r.usersService.Scroll(withParams(ctx, params))
+	if err != nil {
+		return nil, resolveErr(ctx, err)
+	}
+	return &userPageResolver{cursors: cursors{page}, users: *users}, nil
+}
+
+// User gets one user.
+func (r *Resolver) User(ctx context.Context, args struct{ ID graphql.ID }) (*userResolver, error) {
+	user, err := r.usersService.Show(withParams(ctx, idParams("user_id", args.ID)))
+	if err != nil {
+		return nil, resolveErr(ctx, err)
+	}
+	return &userResolver{user: user}, nil
+}
+
+// userInput is the UserInput input.
+type userInput struct {
+	Name string
+}
+
+// CreateUser adds a user.
+func (r *Resolver) CreateUser(ctx context.Context, args struct{ Input userInput }) (*userResolver, error) {
+	user := &models.User{Name: args.Input.Name}
+
+	verrs, err := r.usersService.Insert(requestContext(ctx), user)
+	if err == nil && verrs.HasAny() {
+		err = models.ValidationError(verrs)
+	}
+	if err != nil {
+		return nil, resolveErr(ctx, err)
+	}
+	return &userResolver{user: user}, nil
+}
+
+// UpdateUser renames a user.
+func (r *Resolver) UpdateUser(ctx context.Context, args struct {
+	ID    graphql.ID
+	Input userInput
+}) (*userResolver, error) {
+	user, err := r.usersService.Edit(withParams(ctx, idParams("user_id", args.ID)))
+	if err != nil {
+		return nil, resolveErr(ctx, err)
+	}
+	user.Name = args.Input.Name
+
+	verrs, err := r.usersService.Save(requestContext(ctx), user)
+	if err == nil && verrs.HasAny() {
+		err = models.ValidationError(verrs)
+	}
+	if err != nil {
+		return nil, resolveErr(ctx, err)
+	}
+	return &userResolver{user: user}, nil
+}
+
+// DeleteUser deletes a user.
+func (r *Resolver) DeleteUser(ctx context.Context, args struct{ ID graphql.ID }) (*userResolver, error) {
+	user, err := r.usersService.Destroy(withParams(ctx, idParams("user_id", args.ID)))
+	if err != nil {
+		return nil, resolveErr(ctx, err)
+	}
+	return &userResolver{user: user}, nil
+}
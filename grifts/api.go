@@ -0,0 +1,52 @@
+package grifts
+
+import (
+	"fmt"
+	"time"
+
+	"location_service_v1/ls_v2/models"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/markbates/grift/grift"
+)
+
+var _ = grift.Namespace("api", func() {
+
+	grift.Desc("token", "Creates a bearer token of the JSON API: buffalo task api:token <name>")
+	grift.Add("token", func(c *grift.Context) error {
+		if len(c.Args) != 1 {
+			return fmt.Errorf("usage: buffalo task api:token <name>")
+		}
+
+		token, secret, err := models.NewAPIToken(c.Args[0])
+		if err != nil {
+			return err
+		}
+		verrs, err := models.DB.ValidateAndCreate(token)
+		if err != nil {
+			return err
+		}
+		if verrs.HasAny() {
+			return verrs
+		}
+
+		// the secret is not stored, so this is the only time it can be seen
+		fmt.Printf("token %s created for %q\n%s\n", token.ID, token.Name, secret)
+		return nil
+	})
+
+	grift.Desc("revoke", "Revokes a bearer token of the JSON API: buffalo task api:revoke <id>")
+	grift.Add("revoke", func(c *grift.Context) error {
+		if len(c.Args) != 1 {
+			return fmt.Errorf("usage: buffalo task api:revoke <id>")
+		}
+
+		token := &models.APIToken{}
+		if err := models.DB.Find(token, c.Args[0]); err != nil {
+			return err
+		}
+		token.RevokedAt = nulls.NewTime(time.Now())
+		return models.DB.Update(token)
+	})
+
+})
@@ -1,9 +1,13 @@
 package main
 
 import (
+	"context"
 	"log"
+	"net"
 
 	"location_service_v1/ls_v2/actions"
+
+	"github.com/gobuffalo/envy"
 )
 
 // main is the starting point for your Buffalo application.
@@ -15,6 +19,27 @@ import (
 func main() {
 
 	app := actions.App()
+
+	// gRPC clients connect to a port of their own.
+	lis, err := net.Listen("tcp", envy.Get("GRPC_ADDR", ":50051"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	grpc := actions.GRPCServer()
+	go func() {
+		if err := grpc.Serve(lis); err != nil {
+			log.Fatal(err)
+		}
+	}()
+	defer grpc.Stop()
+
+	// Stop sending webhooks and listening for live events once the app
+	// has shut down.
+	ctx, cancel := context.WithCancel(context.Background())
+	go actions.WebhookWorker().Run(ctx)
+	go actions.LiveEvents().Run(ctx)
+	defer cancel()
+
 	if err := app.Serve(); err != nil {
 		log.Fatal(err)
 	}
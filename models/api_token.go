@@ -0,0 +1,68 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/gobuffalo/pop"
+	"github.com/gobuffalo/validate"
+	"github.com/gobuffalo/validate/validators"
+	"github.com/gofrs/uuid"
+)
+
+// tokenPrefix marks the secrets of API tokens so they are easy to spot,
+// e.g. in leaked configuration.
+const tokenPrefix = "ls_"
+
+// APIToken is a bearer token of the JSON API. Only the SHA-256 hash of the
+// secret is stored, the secret itself is shown once when the token is made.
+type APIToken struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	Name       string     `json:"name" db:"name"`
+	TokenHash  string     `json:"-" db:"token_hash"`
+	LastUsedAt nulls.Time `json:"last_used_at" db:"last_used_at"`
+	RevokedAt  nulls.Time `json:"revoked_at" db:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// TableName overrides the table name pop would derive from APIToken.
+func (t APIToken) TableName() string {
+	return "api_tokens"
+}
+
+// String is not required by pop and may be deleted
+func (t APIToken) String() string {
+	jt, _ := json.Marshal(t)
+	return string(jt)
+}
+
+// NewAPIToken makes a token with a random secret. The secret is returned
+// separately because it is not kept.
+func NewAPIToken(name string) (*APIToken, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, "", err
+	}
+	secret := tokenPrefix + hex.EncodeToString(b)
+	return &APIToken{Name: name, TokenHash: HashToken(secret)}, secret, nil
+}
+
+// HashToken is the stored form of a token secret.
+func HashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// Validate gets run every time you call a "pop.Validate*" (pop.ValidateAndSave, pop.ValidateAndCreate, pop.ValidateAndUpdate) method.
+// This method is not required and may be deleted.
+func (t *APIToken) Validate(tx *pop.Connection) (*validate.Errors, error) {
+	return validate.Validate(
+		&validators.StringIsPresent{Field: t.Name, Name: "Name"},
+		&validators.StringIsPresent{Field: t.TokenHash, Name: "TokenHash"},
+	), nil
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_NewAPIToken(t *testing.T) {
+	token, secret, err := NewAPIToken("ci")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(secret, tokenPrefix) {
+		t.Errorf("secret %q has no prefix", secret)
+	}
+	if token.TokenHash == secret || token.TokenHash != HashToken(secret) {
+		t.Error("the secret must be stored hashed")
+	}
+
+	_, other, _ := NewAPIToken("ci")
+	if other == secret {
+		t.Error("secrets must be random")
+	}
+}
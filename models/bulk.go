@@ -0,0 +1,112 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gofrs/uuid"
+)
+
+// Modes of a PointsBulk. Atomic applies all the operations or none of
+// them, best effort keeps the operations that succeed.
+const (
+	BulkAtomic     = "atomic"
+	BulkBestEffort = "best_effort"
+)
+
+// Operations of a PointsBulk.
+const (
+	BulkCreate = "create"
+	BulkUpdate = "update"
+	BulkDelete = "delete"
+)
+
+// MaxBulkOperations is the largest number of operations in one PointsBulk.
+const MaxBulkOperations = 1000
+
+// PointsBulk is a batch of changes to points, e.g.
+//
+//	{"mode": "best_effort", "operations": [
+//		{"op": "create", "point": {"name": "Tverskaya 7", "city": "Moscow"}},
+//		{"op": "update", "id": "...", "point": {"address": "Tverskaya 7/2"}},
+//		{"op": "delete", "id": "..."}
+//	]}
+type PointsBulk struct {
+	Mode       string           `json:"mode"`
+	Operations []PointOperation `json:"operations"`
+}
+
+// PointOperation is one change of a PointsBulk. Point has the JSON keys of
+// the API PointInput; an update changes only the keys it has.
+type PointOperation struct {
+	Op    string          `json:"op"`
+	ID    uuid.UUID       `json:"id"`
+	Point json.RawMessage `json:"point"`
+}
+
+// Validate checks the shape of the batch before any operation runs. An
+// empty mode is atomic.
+func (b *PointsBulk) Validate() error {
+	switch b.Mode {
+	case "":
+		b.Mode = BulkAtomic
+	case BulkAtomic, BulkBestEffort:
+	default:
+		return BadRequestError(fmt.Errorf("mode must be %q or %q", BulkAtomic, BulkBestEffort))
+	}
+
+	if len(b.Operations) == 0 {
+		return BadRequestError(fmt.Errorf("operations must not be empty"))
+	}
+	if len(b.Operations) > MaxBulkOperations {
+		return BadRequestError(fmt.Errorf("at most %d operations are allowed", MaxBulkOperations))
+	}
+
+	for i, op := range b.Operations {
+		var err error
+		switch op.Op {
+		case BulkCreate:
+			if len(op.Point) == 0 {
+				err = fmt.Errorf("point is required")
+			}
+		case BulkUpdate:
+			if op.ID == uuid.Nil || len(op.Point) == 0 {
+				err = fmt.Errorf("id and point are required")
+			}
+		case BulkDelete:
+			if op.ID == uuid.Nil {
+				err = fmt.Errorf("id is required")
+			}
+		default:
+			err = fmt.Errorf("op must be %q, %q or %q", BulkCreate, BulkUpdate, BulkDelete)
+		}
+		if err != nil {
+			return BadRequestError(fmt.Errorf("operations[%d]: %v", i, err))
+		}
+	}
+	return nil
+}
+
+// PointResult is the outcome of the PointOperation at Index. Point is the
+// point as saved, or as it was before a delete; Err tells why the
+// operation failed.
+type PointResult struct {
+	Index int
+	Op    string
+	Point *Point
+	Err   error
+}
+
+// PointResults is a
+type PointResults []PointResult
+
+// Failed counts the operations that failed.
+func (r PointResults) Failed() int {
+	failed := 0
+	for _, result := range r {
+		if result.Err != nil {
+			failed++
+		}
+	}
+	return failed
+}
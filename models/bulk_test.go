@@ -0,0 +1,47 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func Test_PointsBulk_Validate(t *testing.T) {
+	bulk := &PointsBulk{}
+	err := json.Unmarshal([]byte(`{"operations": [
+		{"op": "create", "point": {"name": "Tverskaya 7"}},
+		{"op": "update", "id": "6ba7b810-9dad-11d1-80b4-00c04fd430c8", "point": {"name": "Arbat 1"}},
+		{"op": "delete", "id": "6ba7b810-9dad-11d1-80b4-00c04fd430c8"}
+	]}`), bulk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bulk.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if bulk.Mode != BulkAtomic {
+		t.Errorf("expected the atomic mode by default, got %q", bulk.Mode)
+	}
+
+	for _, bad := range []PointsBulk{
+		{},
+		{Mode: "sometimes", Operations: []PointOperation{{Op: BulkCreate, Point: json.RawMessage(`{}`)}}},
+		{Operations: []PointOperation{{Op: "upsert"}}},
+		{Operations: []PointOperation{{Op: BulkCreate}}},
+		{Operations: []PointOperation{{Op: BulkUpdate, Point: json.RawMessage(`{}`)}}},
+		{Operations: []PointOperation{{Op: BulkDelete}}},
+		{Operations: make([]PointOperation, MaxBulkOperations+1)},
+	} {
+		var e *Error
+		if err := bad.Validate(); !errors.As(err, &e) || e.Code != CodeBadRequest {
+			t.Errorf("expected a bad request for %+v, got %v", bad, err)
+		}
+	}
+}
+
+func Test_PointResults_Failed(t *testing.T) {
+	results := PointResults{{Index: 0}, {Index: 1, Err: errors.New("invalid")}}
+	if results.Failed() != 1 {
+		t.Fatalf("expected 1 failure, got %d", results.Failed())
+	}
+}
@@ -16,6 +16,7 @@ type Company struct {
 	Name      string    `json:"name" db:"name"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	Version   int       `json:"-" xml:"-" form:"-" db:"version"`
 	Points    []Point   `json:"points,omitempty" has_many:"points"`
 }
 
@@ -42,6 +43,12 @@ func (c *Company) Validate(tx *pop.Connection) (*validate.Errors, error) {
 	), nil
 }
 
+// BeforeUpdate bumps the version, which is the ETag of the company.
+func (c *Company) BeforeUpdate(tx *pop.Connection) error {
+	c.Version++
+	return nil
+}
+
 // ValidateCreate gets run every time you call "pop.ValidateAndCreate" method.
 // This method is not required and may be deleted.
 func (c *Company) ValidateCreate(tx *pop.Connection) (*validate.Errors, error) {
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// Dashboard is the overview shown on the home page.
+type Dashboard struct {
+	Points    int `json:"points"`
+	Companies int `json:"companies"`
+	Users     int `json:"users"`
+
+	// RecentSince is the start of the recent period, RecentPoints the
+	// points created after it.
+	RecentSince  time.Time `json:"recent_since"`
+	RecentPoints int       `json:"recent_points"`
+
+	// ImportRuns are the latest imports, newest first.
+	ImportRuns ImportRuns `json:"import_runs"`
+
+	// Problems counts the points with each data-quality problem, keyed by
+	// the value of the "problem" filter of the points list.
+	Problems map[string]int `json:"problems"`
+}
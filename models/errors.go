@@ -0,0 +1,95 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/gobuffalo/validate"
+)
+
+// ErrorCode tells what kind of failure an Error is. The codes are part of
+// the error bodies of the JSON and XML responses.
+type ErrorCode string
+
+// Codes of the domain errors.
+const (
+	CodeBadRequest         ErrorCode = "bad_request"
+	CodeUnauthorized       ErrorCode = "unauthorized"
+	CodeNotFound           ErrorCode = "not_found"
+	CodeConflict           ErrorCode = "conflict"
+	CodeInvalid            ErrorCode = "validation_failed"
+	CodeUpstream           ErrorCode = "upstream_failed"
+	CodePreconditionFailed ErrorCode = "precondition_failed"
+	CodeRateLimited        ErrorCode = "rate_limited"
+	CodeKeyReused          ErrorCode = "idempotency_key_reused"
+)
+
+// Error is a failure the client can act on, as opposed to an internal
+// error. Fields holds the messages of each invalid field of a validation
+// error. Err is the underlying error, if any; it is logged but not shown.
+type Error struct {
+	Code    ErrorCode
+	Message string
+	Fields  map[string][]string
+	Err     error
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// BadRequestError is a malformed parameter or body.
+func BadRequestError(err error) *Error {
+	return &Error{Code: CodeBadRequest, Message: err.Error()}
+}
+
+// UnauthorizedError is a missing or invalid credential.
+func UnauthorizedError(message string) *Error {
+	return &Error{Code: CodeUnauthorized, Message: message}
+}
+
+// NotFoundError is a missing record, e.g. NotFoundError("point", err).
+func NotFoundError(what string, err error) *Error {
+	return &Error{Code: CodeNotFound, Message: what + " not found", Err: err}
+}
+
+// ConflictError is a change that clashes with the current state of a record.
+func ConflictError(message string) *Error {
+	return &Error{Code: CodeConflict, Message: message}
+}
+
+// ValidationError is input rejected by the Validate methods of the models.
+func ValidationError(verrs *validate.Errors) *Error {
+	return &Error{Code: CodeInvalid, Message: "validation failed", Fields: verrs.Errors}
+}
+
+// UpstreamError is a failure of an external service the request depends on,
+// e.g. the pickpoint postamat list.
+func UpstreamError(service string, err error) *Error {
+	return &Error{Code: CodeUpstream, Message: service + " is unavailable", Err: err}
+}
+
+// PreconditionFailedError is a change whose If-Match header names another
+// version of the record than the current one.
+func PreconditionFailedError(message string) *Error {
+	return &Error{Code: CodePreconditionFailed, Message: message}
+}
+
+// RateLimitedError is a request over the rate limit of its client.
+func RateLimitedError(message string) *Error {
+	return &Error{Code: CodeRateLimited, Message: message}
+}
+
+// KeyReusedError is a request whose Idempotency-Key was first sent with
+// another request.
+func KeyReusedError() *Error {
+	return &Error{Code: CodeKeyReused, Message: "the Idempotency-Key was used with another request"}
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gobuffalo/validate"
+)
+
+func Test_Error(t *testing.T) {
+	cause := errors.New("sql: no rows in result set")
+	err := error(NotFoundError("point", cause))
+
+	var e *Error
+	if !errors.As(err, &e) || e.Code != CodeNotFound {
+		t.Fatalf("got %v", err)
+	}
+	if !errors.Is(err, cause) {
+		t.Error("the cause must be unwrapped")
+	}
+	if e.Message != "point not found" {
+		t.Errorf("the cause must not leak into the message, got %q", e.Message)
+	}
+
+	verrs := validate.NewErrors()
+	verrs.Add("name", "Name can not be blank.")
+	if got := ValidationError(verrs); got.Fields["name"][0] != "Name can not be blank." {
+		t.Errorf("got %v", got.Fields)
+	}
+}
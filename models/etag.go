@@ -0,0 +1,34 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ETag is the entity tag of a version of a record, e.g. "3".
+func ETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// MatchETag reports whether etag is one of the comma separated entity tags
+// of an If-Match or If-None-Match header, or the header is "*". Weak tags
+// (W/"3") only match when weak is true: If-None-Match compares them weakly
+// and If-Match strongly.
+func MatchETag(header, etag string, weak bool) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if strings.HasPrefix(tag, "W/") {
+			if !weak {
+				continue
+			}
+			tag = strings.TrimPrefix(tag, "W/")
+		}
+		if tag == etag {
+			return true
+		}
+	}
+	return false
+}
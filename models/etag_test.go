@@ -0,0 +1,29 @@
+package models
+
+import "testing"
+
+func Test_MatchETag(t *testing.T) {
+	etag := ETag(3)
+	if etag != `"3"` {
+		t.Fatalf("got %s", etag)
+	}
+
+	tests := []struct {
+		header string
+		weak   bool
+		want   bool
+	}{
+		{`"3"`, false, true},
+		{`"2", "3"`, false, true},
+		{`"2"`, false, false},
+		{`*`, false, true},
+		{`W/"3"`, false, false},
+		{`W/"3"`, true, true},
+		{``, true, false},
+	}
+	for _, tt := range tests {
+		if got := MatchETag(tt.header, etag, tt.weak); got != tt.want {
+			t.Errorf("MatchETag(%q, %v) = %v, want %v", tt.header, tt.weak, got, tt.want)
+		}
+	}
+}
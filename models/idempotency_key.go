@@ -0,0 +1,58 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// IdempotencyAbandonAfter is how long the first request of a key may run.
+// A key still running after that is taken to belong to a request that
+// died, and the next request with it runs again.
+const IdempotencyAbandonAfter = time.Minute
+
+// IdempotencyKey is an Idempotency-Key sent by a client with a request to
+// Route, and the response to replay when the request is sent again.
+// RequestHash tells the same request from another one that reuses the key.
+// Status is 0 while the first request runs; Headers are the replayed
+// response headers as a JSON object.
+type IdempotencyKey struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	Client      string    `json:"client" db:"client"`
+	Key         string    `json:"key" db:"key"`
+	Route       string    `json:"route" db:"route"`
+	RequestHash string    `json:"request_hash" db:"request_hash"`
+	Status      int       `json:"status" db:"status"`
+	Headers     string    `json:"headers" db:"headers"`
+	Body        string    `json:"body" db:"body"`
+	ExpiresAt   time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// String is not required by pop and may be deleted
+func (k IdempotencyKey) String() string {
+	jk, _ := json.Marshal(k)
+	return string(jk)
+}
+
+// Running reports whether the first request of the key has no response yet.
+func (k IdempotencyKey) Running() bool {
+	return k.Status == 0
+}
+
+// Reusable reports whether the key may start a request again at now: it
+// has expired, or its first request was abandoned.
+func (k IdempotencyKey) Reusable(now time.Time) bool {
+	if now.After(k.ExpiresAt) {
+		return true
+	}
+	return k.Running() && now.Sub(k.UpdatedAt) > IdempotencyAbandonAfter
+}
+
+// Matches reports whether a request to route with hash is the request the
+// key was first sent with.
+func (k IdempotencyKey) Matches(route, hash string) bool {
+	return k.Route == route && k.RequestHash == hash
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_IdempotencyKey(t *testing.T) {
+	now := time.Now()
+	k := IdempotencyKey{Route: "POST /points", RequestHash: "abc", ExpiresAt: now.Add(time.Hour), UpdatedAt: now}
+
+	if !k.Matches("POST /points", "abc") || k.Matches("POST /points", "abd") || k.Matches("POST /companies", "abc") {
+		t.Error("a key must match its own route and hash only")
+	}
+
+	if !k.Running() || k.Reusable(now) {
+		t.Error("a key just taken must be running and not reusable")
+	}
+	if !k.Reusable(now.Add(IdempotencyAbandonAfter + time.Second)) {
+		t.Error("a key running for too long must be reusable")
+	}
+
+	k.Status = 201
+	if k.Running() || k.Reusable(now.Add(IdempotencyAbandonAfter+time.Second)) {
+		t.Error("a stored response must be replayed until the key expires")
+	}
+	if !k.Reusable(now.Add(2 * time.Hour)) {
+		t.Error("an expired key must be reusable")
+	}
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/gofrs/uuid"
+)
+
+// Outcomes of an ImportRun.
+const (
+	ImportRunning   = "running"
+	ImportSucceeded = "succeeded"
+	ImportFailed    = "failed"
+)
+
+// ImportRun is one load of points from an external source, e.g. the
+// pickpoint postamat list. Created and Failed count the points saved and
+// rejected, Error keeps the reason a failed run stopped.
+type ImportRun struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	Source     string     `json:"source" db:"source"`
+	Status     string     `json:"status" db:"status"`
+	Total      int        `json:"total" db:"total"`
+	Created    int        `json:"created" db:"created"`
+	Failed     int        `json:"failed" db:"failed"`
+	Error      string     `json:"error,omitempty" db:"error"`
+	StartedAt  time.Time  `json:"started_at" db:"started_at"`
+	FinishedAt nulls.Time `json:"finished_at" db:"finished_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// String is not required by pop and may be deleted
+func (i ImportRun) String() string {
+	ji, _ := json.Marshal(i)
+	return string(ji)
+}
+
+// Finish records the outcome of the run. A non nil err marks it failed.
+func (i *ImportRun) Finish(err error) {
+	i.Status = ImportSucceeded
+	if err != nil {
+		i.Status = ImportFailed
+		i.Error = err.Error()
+	}
+	i.FinishedAt = nulls.NewTime(time.Now())
+}
+
+// CreatedAfter is the "created_after" filter of the points list that shows
+// the points saved by the run.
+func (i ImportRun) CreatedAfter() string {
+	return i.StartedAt.UTC().Format(time.RFC3339)
+}
+
+// ImportRuns is not required by pop and may be deleted
+type ImportRuns []ImportRun
+
+// String is not required by pop and may be deleted
+func (i ImportRuns) String() string {
+	ji, _ := json.Marshal(i)
+	return string(ji)
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_ImportRun_Finish(t *testing.T) {
+	run := &ImportRun{Status: ImportRunning}
+	run.Finish(nil)
+	if run.Status != ImportSucceeded || !run.FinishedAt.Valid {
+		t.Errorf("got %q, finished %v", run.Status, run.FinishedAt.Valid)
+	}
+
+	run = &ImportRun{Status: ImportRunning}
+	run.Finish(errors.New("connection refused"))
+	if run.Status != ImportFailed || run.Error != "connection refused" {
+		t.Errorf("got %q with error %q", run.Status, run.Error)
+	}
+}
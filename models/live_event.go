@@ -0,0 +1,34 @@
+package models
+
+import (
+	"encoding/json"
+
+	"github.com/gofrs/uuid"
+)
+
+// LiveEventsChannel is the PostgreSQL NOTIFY channel that carries the live
+// events to every instance of the app.
+const LiveEventsChannel = "live_events"
+
+// EventImportProgress is the live event of a running import. The point
+// events share the names of the webhook events.
+const EventImportProgress = "import.progress"
+
+// LiveEvent is an event pushed to the open /events streams. CompanyID and
+// City are those of the point, for the filters of the streams; Data is the
+// point or import run the event is about.
+type LiveEvent struct {
+	Type      string          `json:"type"`
+	CompanyID uuid.UUID       `json:"company_id"`
+	City      string          `json:"city"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// NewLiveEvent is a
+func NewLiveEvent(typ string, companyID uuid.UUID, city string, data interface{}) (*LiveEvent, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return &LiveEvent{Type: typ, CompanyID: companyID, City: city, Data: b}, nil
+}
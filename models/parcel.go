@@ -0,0 +1,66 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Parcel is a box the customer wants to send to a Point.
+// Dimensions are in millimetres and the weight is in grams.
+type Parcel struct {
+	Length int
+	Width  int
+	Height int
+	Weight int
+}
+
+// ParseParcel builds a Parcel from the "parcel=LxWxH" and "weight_g" request
+// parameters. Either of them may be empty, in which case it is left as zero.
+func ParseParcel(dimensions string, weight string) (*Parcel, error) {
+	parcel := &Parcel{}
+
+	if dimensions = strings.TrimSpace(dimensions); dimensions != "" {
+		parts := strings.Split(strings.ToLower(dimensions), "x")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("parcel must be in the LxWxH format, got %q", dimensions)
+		}
+
+		sizes := make([]int, 3)
+		for i, part := range parts {
+			size, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || size <= 0 {
+				return nil, fmt.Errorf("parcel dimension %q must be a positive integer", part)
+			}
+			sizes[i] = size
+		}
+		parcel.Length, parcel.Width, parcel.Height = sizes[0], sizes[1], sizes[2]
+	}
+
+	if weight = strings.TrimSpace(weight); weight != "" {
+		w, err := strconv.Atoi(weight)
+		if err != nil || w < 0 {
+			return nil, fmt.Errorf("weight_g %q must be a non-negative integer", weight)
+		}
+		parcel.Weight = w
+	}
+
+	return parcel, nil
+}
+
+// Sorted returns the parcel dimensions from the largest to the smallest.
+// A box fits into a cell in some rotation exactly when each of its sorted
+// dimensions is not larger than the matching sorted dimension of the cell.
+func (p Parcel) Sorted() [3]int {
+	d := [3]int{p.Length, p.Width, p.Height}
+	if d[0] < d[1] {
+		d[0], d[1] = d[1], d[0]
+	}
+	if d[1] < d[2] {
+		d[1], d[2] = d[2], d[1]
+	}
+	if d[0] < d[1] {
+		d[0], d[1] = d[1], d[0]
+	}
+	return d
+}
@@ -0,0 +1,29 @@
+package models
+
+import "testing"
+
+func Test_ParseParcel(t *testing.T) {
+	parcel, err := ParseParcel("300x100X200", "1500")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parcel.Length != 300 || parcel.Width != 100 || parcel.Height != 200 || parcel.Weight != 1500 {
+		t.Fatalf("unexpected parcel %+v", parcel)
+	}
+
+	for _, bad := range []string{"300x100", "300x0x200", "axbxc"} {
+		if _, err := ParseParcel(bad, ""); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+	if _, err := ParseParcel("", "-1"); err == nil {
+		t.Error("expected an error for a negative weight")
+	}
+}
+
+func Test_Parcel_Sorted(t *testing.T) {
+	parcel := Parcel{Length: 100, Width: 300, Height: 200}
+	if got := parcel.Sorted(); got != [3]int{300, 200, 100} {
+		t.Fatalf("expected [300 200 100], got %v", got)
+	}
+}
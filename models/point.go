@@ -11,6 +11,8 @@ import (
 )
 
 // Point is used by pop to map your .model.Name.Proper.Pluralize.Underscore database table to your go code.
+// MaxLength, MaxWidth and MaxHeight are the largest cell of the point in millimetres
+// and MaxWeight is in grams. Zero means the limit is not known.
 type Point struct {
 	ID             uuid.UUID `json:"id" db:"id"`
 	Name           string    `json:"name" db:"name"`
@@ -20,8 +22,13 @@ type Point struct {
 	OutDescription string    `json:"outDescription" db:"out_description"`
 	OwnerID        int       `json:"ownerId" db:"owner_id"`
 	OwnerName      string    `json:"ownerName" db:"owner_name"`
+	MaxLength      int       `json:"max_length" db:"max_length"`
+	MaxWidth       int       `json:"max_width" db:"max_width"`
+	MaxHeight      int       `json:"max_height" db:"max_height"`
+	MaxWeight      int       `json:"max_weight" db:"max_weight"`
 	CreatedAt      time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+	Version        int       `json:"-" xml:"-" form:"-" db:"version"`
 	CompanyID      uuid.UUID `json:"company_id" db:"company_id"`
 	Company        *Company  `json:"company,omitempty" belongs_to:"company"`
 }
@@ -44,6 +51,15 @@ func (p Point) String() string {
 	return string(jp)
 }
 
+// CompanyName is the name of the loaded Company, or an empty string
+// when the company has not been loaded.
+func (p Point) CompanyName() string {
+	if p.Company == nil {
+		return ""
+	}
+	return p.Company.Name
+}
+
 // Points is a
 type Points []Point
 
@@ -61,9 +77,19 @@ func (p Points) String() string {
 func (p *Point) Validate(tx *pop.Connection) (*validate.Errors, error) {
 	return validate.Validate(
 		&validators.StringIsPresent{Field: p.Name, Name: "Name"},
+		&validators.IntIsGreaterThan{Field: p.MaxLength, Name: "MaxLength", Compared: -1, Message: "MaxLength must not be negative."},
+		&validators.IntIsGreaterThan{Field: p.MaxWidth, Name: "MaxWidth", Compared: -1, Message: "MaxWidth must not be negative."},
+		&validators.IntIsGreaterThan{Field: p.MaxHeight, Name: "MaxHeight", Compared: -1, Message: "MaxHeight must not be negative."},
+		&validators.IntIsGreaterThan{Field: p.MaxWeight, Name: "MaxWeight", Compared: -1, Message: "MaxWeight must not be negative."},
 	), nil
 }
 
+// BeforeUpdate bumps the version, which is the ETag of the point.
+func (p *Point) BeforeUpdate(tx *pop.Connection) error {
+	p.Version++
+	return nil
+}
+
 // ValidateCreate gets run every time you call "pop.ValidateAndCreate" method.
 // This method is not required and may be deleted.
 func (p *Point) ValidateCreate(tx *pop.Connection) (*validate.Errors, error) {
@@ -75,3 +101,14 @@ func (p *Point) ValidateCreate(tx *pop.Connection) (*validate.Errors, error) {
 func (p *Point) ValidateUpdate(tx *pop.Connection) (*validate.Errors, error) {
 	return validate.NewErrors(), nil
 }
+
+// PointSearchResult is a Point found by the full-text search together with
+// its rank and a snippet where the matched words are wrapped in <mark>.
+type PointSearchResult struct {
+	Point
+	Rank    float64 `json:"rank" db:"rank"`
+	Snippet string  `json:"snippet" db:"snippet"`
+}
+
+// PointSearchResults is a
+type PointSearchResults []PointSearchResult
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// PointChange is a row of the change log of points. The points table
+// trigger adds one for every insert, update and delete, in the transaction
+// of the change. TxID is that transaction, which orders the log by commit
+// visibility rather than by ID.
+type PointChange struct {
+	ID        int64     `json:"id" db:"id"`
+	TxID      int64     `json:"txid" db:"txid"`
+	PointID   uuid.UUID `json:"point_id" db:"point_id"`
+	Deleted   bool      `json:"deleted" db:"deleted"`
+	ChangedAt time.Time `json:"changed_at" db:"changed_at"`
+}
+
+// PointChanges is a
+type PointChanges []PointChange
+
+// String is not required by pop and may be deleted
+func (p PointChanges) String() string {
+	jp, _ := json.Marshal(p)
+	return string(jp)
+}
+
+// PointDeletion is a tombstone of the change feed: a point that is gone.
+type PointDeletion struct {
+	ID        uuid.UUID `json:"id" xml:"id"`
+	DeletedAt time.Time `json:"deleted_at" xml:"deleted_at"`
+}
+
+// PointChangeFeed is a page of the change feed. Upserts are the current
+// state of the points changed since the token of the request and
+// Deletions the points deleted since then; a point is in at most one of
+// them. Next is the token of the following request.
+type PointChangeFeed struct {
+	XMLName   xml.Name        `json:"-" xml:"changes"`
+	Upserts   Points          `json:"upserts" xml:"upserts>point"`
+	Deletions []PointDeletion `json:"deletions" xml:"deletions>deletion"`
+	Next      string          `json:"next" xml:"next"`
+	HasMore   bool            `json:"has_more" xml:"has_more"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "encoding/json"
+
+// PointStat is the number of points in one group of the statistics, with
+// how many of them were created or updated since the requested date.
+// Company, CompanyID and City are empty when the stats are not grouped by them.
+type PointStat struct {
+	CompanyID string `json:"company_id,omitempty" db:"company_id"`
+	Company   string `json:"company,omitempty" db:"company"`
+	City      string `json:"city,omitempty" db:"city"`
+	Points    int    `json:"points" db:"points"`
+	Created   int    `json:"created" db:"created"`
+	Updated   int    `json:"updated" db:"updated"`
+}
+
+// PointStats is a
+type PointStats []PointStat
+
+// String is not required by pop and may be deleted
+func (p PointStats) String() string {
+	jp, _ := json.Marshal(p)
+	return string(jp)
+}
@@ -3,5 +3,15 @@ package models
 import "testing"
 
 func Test_Point(t *testing.T) {
-	t.Fatal("This test needs to be implemented!")
+	verrs, err := (&Point{Name: "Tverskaya 7", MaxWeight: 5000}).Validate(nil)
+	if err != nil || verrs.HasAny() {
+		t.Fatalf("expected a valid point, got %v, %v", verrs, err)
+	}
+
+	verrs, _ = (&Point{MaxLength: -1}).Validate(nil)
+	for _, key := range []string{"name", "max_length"} {
+		if verrs.Get(key) == nil {
+			t.Errorf("expected an error for %s, got %v", key, verrs.Errors)
+		}
+	}
 }
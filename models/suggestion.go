@@ -0,0 +1,18 @@
+package models
+
+import "encoding/json"
+
+// Suggestion is an autocomplete value with the number of points that have it.
+type Suggestion struct {
+	Value  string `json:"value" db:"value"`
+	Points int    `json:"points" db:"points"`
+}
+
+// Suggestions is a
+type Suggestions []Suggestion
+
+// String is not required by pop and may be deleted
+func (s Suggestions) String() string {
+	js, _ := json.Marshal(s)
+	return string(js)
+}
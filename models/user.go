@@ -14,6 +14,7 @@ type User struct {
 	Name      string    `json:"name" db:"name"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	Version   int       `json:"-" xml:"-" form:"-" db:"version"`
 }
 
 // String is not required by pop and may be deleted
@@ -37,6 +38,12 @@ func (u *User) Validate(tx *pop.Connection) (*validate.Errors, error) {
 	return validate.NewErrors(), nil
 }
 
+// BeforeUpdate bumps the version, which is the ETag of the user.
+func (u *User) BeforeUpdate(tx *pop.Connection) error {
+	u.Version++
+	return nil
+}
+
 // ValidateCreate gets run every time you call "pop.ValidateAndCreate" method.
 // This method is not required and may be deleted.
 func (u *User) ValidateCreate(tx *pop.Connection) (*validate.Errors, error) {
@@ -0,0 +1,265 @@
+package models
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/gobuffalo/pop"
+	"github.com/gobuffalo/pop/slices"
+	"github.com/gobuffalo/validate"
+	"github.com/gobuffalo/validate/validators"
+	"github.com/gofrs/uuid"
+)
+
+// Events of the webhooks.
+const (
+	EventPointCreated   = "point.created"
+	EventPointUpdated   = "point.updated"
+	EventPointDeleted   = "point.deleted"
+	EventCompanyCreated = "company.created"
+	EventCompanyUpdated = "company.updated"
+	EventCompanyDeleted = "company.deleted"
+)
+
+// WebhookEvents are the events a subscription can ask for.
+var WebhookEvents = []string{
+	EventPointCreated, EventPointUpdated, EventPointDeleted,
+	EventCompanyCreated, EventCompanyUpdated, EventCompanyDeleted,
+}
+
+// WebhookSubscription is a partner URL that gets the events it lists, or
+// every event when Events is empty. Deliveries are signed with Secret.
+type WebhookSubscription struct {
+	ID        uuid.UUID     `json:"id" db:"id"`
+	URL       string        `json:"url" db:"url"`
+	Events    slices.String `json:"events" db:"events"`
+	Secret    string        `json:"-" db:"secret"`
+	Active    bool          `json:"active" db:"active"`
+	CreatedAt time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at" db:"updated_at"`
+}
+
+// String is not required by pop and may be deleted
+func (w WebhookSubscription) String() string {
+	jw, _ := json.Marshal(w)
+	return string(jw)
+}
+
+// WebhookSubscriptions is a
+type WebhookSubscriptions []WebhookSubscription
+
+// Subscribes reports whether the subscription gets the event.
+func (w WebhookSubscription) Subscribes(event string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// BeforeCreate gives the subscription a random secret if it has none.
+func (w *WebhookSubscription) BeforeCreate(tx *pop.Connection) error {
+	if w.Secret != "" {
+		return nil
+	}
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return err
+	}
+	w.Secret = "whsec_" + hex.EncodeToString(b)
+	return nil
+}
+
+// Validate gets run every time you call a "pop.Validate*" (pop.ValidateAndSave, pop.ValidateAndCreate, pop.ValidateAndUpdate) method.
+// The events are trimmed first, as the form sends them comma separated.
+func (w *WebhookSubscription) Validate(tx *pop.Connection) (*validate.Errors, error) {
+	for i := range w.Events {
+		w.Events[i] = strings.TrimSpace(w.Events[i])
+	}
+
+	verrs := validate.Validate(
+		&validators.StringIsPresent{Field: w.URL, Name: "URL"},
+	)
+	if w.URL != "" {
+		u, err := url.Parse(w.URL)
+		switch {
+		case err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "":
+			verrs.Add("url", "URL must be an http or https URL.")
+		case !publicHost(u.Hostname()):
+			verrs.Add("url", "URL must not point to a private, loopback or link-local address.")
+		}
+	}
+	for _, e := range w.Events {
+		if !isWebhookEvent(e) {
+			verrs.Add("events", fmt.Sprintf("%q is not an event, use %s.", e, strings.Join(WebhookEvents, ", ")))
+		}
+	}
+	return verrs, nil
+}
+
+// publicHost reports whether host may be the host of a subscription. A
+// name is only resolved when the deliveries are sent, where the worker
+// checks its addresses again.
+func publicHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return PublicIP(ip)
+	}
+	return true
+}
+
+// PublicIP reports whether webhooks may be sent to ip: it is not private,
+// loopback, link-local or unspecified, so that a subscription cannot reach
+// the services next to this one.
+func PublicIP(ip net.IP) bool {
+	return !(ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsUnspecified())
+}
+
+// isWebhookEvent reports whether e is one of WebhookEvents.
+func isWebhookEvent(e string) bool {
+	for _, known := range WebhookEvents {
+		if e == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Status of a webhook delivery.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// Retries of the webhook deliveries: the first retry is RetryDelay after a
+// failed attempt and each next one waits twice as long, until
+// MaxDeliveryAttempts attempts have failed.
+const (
+	MaxDeliveryAttempts = 8
+	RetryDelay          = 30 * time.Second
+)
+
+// WebhookDelivery is one event sent, or to be sent, to a subscription.
+// Payload is the JSON body of the request. The fields of the last attempt
+// make up the delivery log.
+type WebhookDelivery struct {
+	ID             uuid.UUID    `json:"id" db:"id"`
+	SubscriptionID uuid.UUID    `json:"subscription_id" db:"subscription_id"`
+	Event          string       `json:"event" db:"event"`
+	Payload        string       `json:"payload" db:"payload"`
+	Status         string       `json:"status" db:"status"`
+	Attempts       int          `json:"attempts" db:"attempts"`
+	NextAttemptAt  time.Time    `json:"next_attempt_at" db:"next_attempt_at"`
+	ResponseStatus int          `json:"response_status" db:"response_status"`
+	Error          nulls.String `json:"error" db:"error"`
+	DeliveredAt    nulls.Time   `json:"delivered_at" db:"delivered_at"`
+	CreatedAt      time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at" db:"updated_at"`
+}
+
+// String is not required by pop and may be deleted
+func (d WebhookDelivery) String() string {
+	jd, _ := json.Marshal(d)
+	return string(jd)
+}
+
+// WebhookDeliveries is a
+type WebhookDeliveries []WebhookDelivery
+
+// webhookPayload is the body of a delivery.
+type webhookPayload struct {
+	ID        uuid.UUID   `json:"id"`
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// NewWebhookDelivery makes a pending delivery of the event with data, the
+// record it is about, to a subscription.
+func NewWebhookDelivery(subscriptionID uuid.UUID, event string, data interface{}, now time.Time) (*WebhookDelivery, error) {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(webhookPayload{ID: id, Event: event, CreatedAt: now, Data: data})
+	if err != nil {
+		return nil, err
+	}
+	return &WebhookDelivery{
+		ID:             id,
+		SubscriptionID: subscriptionID,
+		Event:          event,
+		Payload:        string(payload),
+		Status:         DeliveryPending,
+		NextAttemptAt:  now,
+	}, nil
+}
+
+// Resend makes a new pending delivery with the payload of d. The payload
+// keeps its id, so receivers can tell the event was sent before.
+func (d WebhookDelivery) Resend(now time.Time) (*WebhookDelivery, error) {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return nil, err
+	}
+	return &WebhookDelivery{
+		ID:             id,
+		SubscriptionID: d.SubscriptionID,
+		Event:          d.Event,
+		Payload:        d.Payload,
+		Status:         DeliveryPending,
+		NextAttemptAt:  now,
+	}, nil
+}
+
+// Delivered records a successful attempt.
+func (d *WebhookDelivery) Delivered(status int, now time.Time) {
+	d.Attempts++
+	d.Status = DeliveryDelivered
+	d.ResponseStatus = status
+	d.Error = nulls.String{}
+	d.DeliveredAt = nulls.NewTime(now)
+}
+
+// Failed records a failed attempt and schedules the next one, or gives up
+// after MaxDeliveryAttempts attempts. status is 0 when there was no
+// response.
+func (d *WebhookDelivery) Failed(status int, err error, now time.Time) {
+	d.Attempts++
+	d.ResponseStatus = status
+	d.Error = nulls.NewString(err.Error())
+	if d.Attempts >= MaxDeliveryAttempts {
+		d.Status = DeliveryFailed
+		return
+	}
+	d.NextAttemptAt = now.Add(RetryDelay << uint(d.Attempts-1))
+}
+
+// SignWebhook is the signature of a delivery body sent at timestamp: the
+// hex HMAC-SHA256 of "<timestamp>.<body>" with the subscription secret.
+// Receivers compute it again to check the X-Webhook-Signature header.
+func SignWebhook(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10) + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
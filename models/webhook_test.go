@@ -0,0 +1,106 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+func Test_WebhookSubscription(t *testing.T) {
+	all := WebhookSubscription{URL: "https://example.com/hooks"}
+	if !all.Subscribes(EventCompanyDeleted) {
+		t.Error("no events must mean every event")
+	}
+
+	w := WebhookSubscription{URL: "https://example.com/hooks", Events: []string{" point.created", "point.deleted"}}
+	verrs, err := w.Validate(nil)
+	if err != nil || verrs.HasAny() {
+		t.Fatalf("got %v, %v", verrs, err)
+	}
+	if !w.Subscribes(EventPointCreated) || w.Subscribes(EventPointUpdated) {
+		t.Errorf("got %v", w.Events)
+	}
+
+	w = WebhookSubscription{URL: "ftp://example.com", Events: []string{"point.closed"}}
+	verrs, _ = w.Validate(nil)
+	if len(verrs.Get("url")) == 0 || len(verrs.Get("events")) == 0 {
+		t.Errorf("got %v", verrs)
+	}
+
+	for _, u := range []string{"http://localhost:3000/hooks", "http://127.0.0.1/hooks", "http://10.0.0.5/hooks",
+		"http://192.168.1.1/hooks", "http://169.254.169.254/latest/meta-data", "http://[::1]/hooks", "http://[fe80::1]/hooks", "http://0.0.0.0/hooks"} {
+		w = WebhookSubscription{URL: u}
+		verrs, _ = w.Validate(nil)
+		if len(verrs.Get("url")) == 0 {
+			t.Errorf("%s must be rejected", u)
+		}
+	}
+	w = WebhookSubscription{URL: "https://93.184.216.34/hooks"}
+	if verrs, _ = w.Validate(nil); verrs.HasAny() {
+		t.Errorf("got %v", verrs)
+	}
+}
+
+func Test_WebhookDelivery_Failed(t *testing.T) {
+	now := time.Date(2020, 4, 8, 12, 0, 0, 0, time.UTC)
+	d, err := NewWebhookDelivery(uuid.Must(uuid.NewV4()), EventPointCreated, map[string]string{"name": "Tverskaya 7"}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload := struct {
+		ID    uuid.UUID         `json:"id"`
+		Event string            `json:"event"`
+		Data  map[string]string `json:"data"`
+	}{}
+	if err := json.Unmarshal([]byte(d.Payload), &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.ID != d.ID || payload.Event != EventPointCreated || payload.Data["name"] != "Tverskaya 7" {
+		t.Errorf("got %s", d.Payload)
+	}
+
+	d.Failed(500, errors.New("500 Internal Server Error"), now)
+	if d.Status != DeliveryPending || !d.NextAttemptAt.Equal(now.Add(RetryDelay)) {
+		t.Errorf("got %s at %s", d.Status, d.NextAttemptAt)
+	}
+	d.Failed(0, errors.New("timeout"), now)
+	if !d.NextAttemptAt.Equal(now.Add(2 * RetryDelay)) {
+		t.Errorf("the delay must double, got %s", d.NextAttemptAt)
+	}
+
+	for d.Status == DeliveryPending {
+		d.Failed(503, errors.New("503 Service Unavailable"), now)
+	}
+	if d.Status != DeliveryFailed || d.Attempts != MaxDeliveryAttempts {
+		t.Errorf("got %s after %d attempts", d.Status, d.Attempts)
+	}
+
+	again, err := d.Resend(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.ID == d.ID || again.Payload != d.Payload || again.Status != DeliveryPending || again.Attempts != 0 {
+		t.Errorf("got %v", again)
+	}
+}
+
+func Test_SignWebhook(t *testing.T) {
+	at := time.Unix(1586347200, 0)
+	got := SignWebhook("whsec_test", at, []byte(`{"event":"point.created"}`))
+	if got != SignWebhook("whsec_test", at, []byte(`{"event":"point.created"}`)) {
+		t.Error("the signature must not change")
+	}
+	if got == SignWebhook("whsec_other", at, []byte(`{"event":"point.created"}`)) {
+		t.Error("the signature must depend on the secret")
+	}
+	if got == SignWebhook("whsec_test", at.Add(time.Second), []byte(`{"event":"point.created"}`)) {
+		t.Error("the signature must depend on the timestamp")
+	}
+	if len(got) != len("sha256=")+64 {
+		t.Errorf("got %s", got)
+	}
+}
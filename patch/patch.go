@@ -0,0 +1,321 @@
+// Package patch applies JSON Merge Patch (RFC 7396) and JSON Patch
+// (RFC 6902) documents to JSON values.
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Media types of the patch documents.
+const (
+	MergePatchType = "application/merge-patch+json"
+	JSONPatchType  = "application/json-patch+json"
+)
+
+// ErrTestFailed is returned when a "test" operation of a JSON Patch does
+// not match the document.
+var ErrTestFailed = errors.New("test failed")
+
+// errNotFound is a JSON Pointer to a location that does not exist.
+var errNotFound = errors.New("path not found")
+
+// Apply patches doc with p, a JSON Patch if contentType is JSONPatchType
+// and a JSON Merge Patch otherwise.
+func Apply(doc, p []byte, contentType string) ([]byte, error) {
+	if strings.HasPrefix(contentType, JSONPatchType) {
+		return JSONPatch(doc, p)
+	}
+	return Merge(doc, p)
+}
+
+// Merge applies the JSON Merge Patch p to doc: the keys of p replace
+// those of doc, recursively for objects, and null removes a key.
+func Merge(doc, p []byte) ([]byte, error) {
+	var target, patch interface{}
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(p, &patch); err != nil {
+		return nil, fmt.Errorf("invalid merge patch: %v", err)
+	}
+	return json.Marshal(merge(target, patch))
+}
+
+func merge(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = map[string]interface{}{}
+	}
+	for key, value := range p {
+		if value == nil {
+			delete(t, key)
+		} else {
+			t[key] = merge(t[key], value)
+		}
+	}
+	return t
+}
+
+// operation is one operation of a JSON Patch. Value is a pointer so that
+// a missing value can be told from null.
+type operation struct {
+	Op    string           `json:"op"`
+	Path  *string          `json:"path"`
+	From  *string          `json:"from"`
+	Value *json.RawMessage `json:"value"`
+}
+
+// JSONPatch applies the operations of the JSON Patch p to doc in order.
+// The document is left alone when one of them fails.
+func JSONPatch(doc, p []byte) ([]byte, error) {
+	var root interface{}
+	if err := json.Unmarshal(doc, &root); err != nil {
+		return nil, err
+	}
+	var ops []operation
+	if err := json.Unmarshal(p, &ops); err != nil {
+		return nil, fmt.Errorf("invalid JSON Patch: %v", err)
+	}
+
+	for i, op := range ops {
+		var err error
+		if root, err = op.apply(root); err != nil {
+			return nil, fmt.Errorf("operation %d (%s): %w", i, op.Op, err)
+		}
+	}
+	return json.Marshal(root)
+}
+
+func (op operation) apply(root interface{}) (interface{}, error) {
+	if op.Path == nil {
+		return nil, errors.New("path is required")
+	}
+	path, err := parsePointer(*op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, errors.New("value is required")
+		}
+		if err := json.Unmarshal(*op.Value, &value); err != nil {
+			return nil, err
+		}
+	case "move", "copy":
+		if op.From == nil {
+			return nil, errors.New("from is required")
+		}
+		from, err := parsePointer(*op.From)
+		if err != nil {
+			return nil, err
+		}
+		if value, err = get(root, from); err != nil {
+			return nil, err
+		}
+		if op.Op == "move" {
+			if strings.HasPrefix(*op.Path, *op.From+"/") {
+				return nil, errors.New("cannot move a value into itself")
+			}
+			if root, _, err = remove(root, from); err != nil {
+				return nil, err
+			}
+		} else {
+			value = deepCopy(value)
+		}
+	case "remove":
+	default:
+		return nil, fmt.Errorf("unknown op %q", op.Op)
+	}
+
+	switch op.Op {
+	case "remove":
+		root, _, err = remove(root, path)
+		return root, err
+	case "replace":
+		if root, _, err = remove(root, path); err != nil {
+			return nil, err
+		}
+		return add(root, path, value)
+	case "test":
+		current, err := get(root, path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(current, value) {
+			return nil, ErrTestFailed
+		}
+		return root, nil
+	}
+	return add(root, path, value)
+}
+
+// parsePointer splits a JSON Pointer (RFC 6901) into its unescaped tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("invalid path %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+	}
+	return tokens, nil
+}
+
+// index is the array index of token, at most max.
+func index(token string, max int) (int, error) {
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i > max || (len(token) > 1 && token[0] == '0') {
+		return 0, errNotFound
+	}
+	return i, nil
+}
+
+// get is the value at path.
+func get(node interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			child, ok := n[token]
+			if !ok {
+				return nil, errNotFound
+			}
+			node = child
+		case []interface{}:
+			i, err := index(token, len(n)-1)
+			if err != nil {
+				return nil, err
+			}
+			node = n[i]
+		default:
+			return nil, errNotFound
+		}
+	}
+	return node, nil
+}
+
+// add puts value at path and returns the new node. "-" appends to an
+// array.
+func add(node interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	token, rest := path[0], path[1:]
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if len(rest) == 0 {
+			n[token] = value
+			return n, nil
+		}
+		child, ok := n[token]
+		if !ok {
+			return nil, errNotFound
+		}
+		child, err := add(child, rest, value)
+		if err != nil {
+			return nil, err
+		}
+		n[token] = child
+		return n, nil
+	case []interface{}:
+		if len(rest) == 0 {
+			i := len(n)
+			if token != "-" {
+				var err error
+				if i, err = index(token, len(n)); err != nil {
+					return nil, err
+				}
+			}
+			n = append(n, nil)
+			copy(n[i+1:], n[i:])
+			n[i] = value
+			return n, nil
+		}
+		i, err := index(token, len(n)-1)
+		if err != nil {
+			return nil, err
+		}
+		if n[i], err = add(n[i], rest, value); err != nil {
+			return nil, err
+		}
+		return n, nil
+	}
+	return nil, errNotFound
+}
+
+// remove takes the value at path out and returns the new node and the
+// removed value.
+func remove(node interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, node, nil
+	}
+	token, rest := path[0], path[1:]
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		child, ok := n[token]
+		if !ok {
+			return nil, nil, errNotFound
+		}
+		if len(rest) == 0 {
+			delete(n, token)
+			return n, child, nil
+		}
+		child, removed, err := remove(child, rest)
+		if err != nil {
+			return nil, nil, err
+		}
+		n[token] = child
+		return n, removed, nil
+	case []interface{}:
+		i, err := index(token, len(n)-1)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(rest) == 0 {
+			removed := n[i]
+			return append(n[:i], n[i+1:]...), removed, nil
+		}
+		child, removed, err := remove(n[i], rest)
+		if err != nil {
+			return nil, nil, err
+		}
+		n[i] = child
+		return n, removed, nil
+	}
+	return nil, nil, errNotFound
+}
+
+// deepCopy copies a decoded JSON value.
+func deepCopy(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		c := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			c[key] = deepCopy(child)
+		}
+		return c
+	case []interface{}:
+		c := make([]interface{}, len(v))
+		for i, child := range v {
+			c[i] = deepCopy(child)
+		}
+		return c
+	}
+	return value
+}
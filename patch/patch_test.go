@@ -0,0 +1,107 @@
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// equalJSON compares two JSON documents regardless of key order.
+func equalJSON(t *testing.T, got []byte, want string) {
+	t.Helper()
+	var g, w interface{}
+	if err := json.Unmarshal(got, &g); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(want), &w); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(g, w) {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func Test_Merge(t *testing.T) {
+	// the example of RFC 7396, section 3
+	doc := `{"title": "Goodbye!", "author": {"givenName": "John", "familyName": "Doe"}, "tags": ["example", "sample"], "content": "This will be unchanged"}`
+	p := `{"title": "Hello!", "phoneNumber": "+01-123-456-7890", "author": {"familyName": null}, "tags": ["example"]}`
+
+	got, err := Merge([]byte(doc), []byte(p))
+	if err != nil {
+		t.Fatal(err)
+	}
+	equalJSON(t, got, `{"title": "Hello!", "author": {"givenName": "John"}, "tags": ["example"], "content": "This will be unchanged", "phoneNumber": "+01-123-456-7890"}`)
+
+	if _, err := Merge([]byte(doc), []byte(`{"title":`)); err == nil {
+		t.Error("expected an error for an invalid patch")
+	}
+}
+
+func Test_JSONPatch(t *testing.T) {
+	tests := []struct {
+		doc, patch, want string
+	}{
+		{`{"foo": "bar"}`, `[{"op": "add", "path": "/baz", "value": "qux"}]`, `{"baz": "qux", "foo": "bar"}`},
+		{`{"foo": ["bar", "baz"]}`, `[{"op": "add", "path": "/foo/1", "value": "qux"}]`, `{"foo": ["bar", "qux", "baz"]}`},
+		{`{"foo": ["bar"]}`, `[{"op": "add", "path": "/foo/-", "value": "qux"}]`, `{"foo": ["bar", "qux"]}`},
+		{`{"baz": "qux", "foo": "bar"}`, `[{"op": "remove", "path": "/baz"}]`, `{"foo": "bar"}`},
+		{`{"foo": ["bar", "qux", "baz"]}`, `[{"op": "remove", "path": "/foo/1"}]`, `{"foo": ["bar", "baz"]}`},
+		{`{"baz": "qux", "foo": "bar"}`, `[{"op": "replace", "path": "/baz", "value": "boo"}]`, `{"baz": "boo", "foo": "bar"}`},
+		{`{"foo": {"bar": "baz", "waldo": "fred"}, "qux": {"corge": "grault"}}`, `[{"op": "move", "from": "/foo/waldo", "path": "/qux/thud"}]`, `{"foo": {"bar": "baz"}, "qux": {"corge": "grault", "thud": "fred"}}`},
+		{`{"foo": ["all", "grass", "cows", "eat"]}`, `[{"op": "move", "from": "/foo/1", "path": "/foo/3"}]`, `{"foo": ["all", "cows", "eat", "grass"]}`},
+		{`{"foo": {"bar": 1}}`, `[{"op": "copy", "from": "/foo", "path": "/baz"}, {"op": "replace", "path": "/baz/bar", "value": 2}]`, `{"foo": {"bar": 1}, "baz": {"bar": 2}}`},
+		{`{"foo": null}`, `[{"op": "add", "path": "/foo", "value": 1}]`, `{"foo": 1}`},
+		{`{"a/b": 1, "m~n": 2}`, `[{"op": "test", "path": "/a~1b", "value": 1}, {"op": "remove", "path": "/m~0n"}]`, `{"a/b": 1}`},
+		{`{"foo": "bar"}`, `[{"op": "replace", "path": "", "value": {"baz": "qux"}}]`, `{"baz": "qux"}`},
+	}
+	for _, tt := range tests {
+		got, err := JSONPatch([]byte(tt.doc), []byte(tt.patch))
+		if err != nil {
+			t.Errorf("%s: %v", tt.patch, err)
+			continue
+		}
+		equalJSON(t, got, tt.want)
+	}
+}
+
+func Test_JSONPatch_Errors(t *testing.T) {
+	doc := []byte(`{"foo": ["bar"], "baz": "qux"}`)
+	for _, p := range []string{
+		`{"op": "add"}`,
+		`[{"op": "add", "path": "/nope/x", "value": 1}]`,
+		`[{"op": "add", "path": "/foo/2", "value": 1}]`,
+		`[{"op": "add", "path": "/foo/01", "value": 1}]`,
+		`[{"op": "add", "path": "/baz"}]`,
+		`[{"op": "remove", "path": "/nope"}]`,
+		`[{"op": "replace", "path": "/nope", "value": 1}]`,
+		`[{"op": "move", "from": "/foo", "path": "/foo/0"}]`,
+		`[{"op": "upsert", "path": "/baz", "value": 1}]`,
+		`[{"op": "add", "path": "baz", "value": 1}]`,
+	} {
+		if _, err := JSONPatch(doc, []byte(p)); err == nil {
+			t.Errorf("expected an error for %s", p)
+		}
+	}
+
+	_, err := JSONPatch(doc, []byte(`[{"op": "test", "path": "/baz", "value": "quux"}]`))
+	if !errors.Is(err, ErrTestFailed) {
+		t.Errorf("expected ErrTestFailed, got %v", err)
+	}
+}
+
+func Test_Apply(t *testing.T) {
+	doc := []byte(`{"name": "Tverskaya 7", "ownerId": 5}`)
+
+	got, err := Apply(doc, []byte(`{"name": "Arbat 1"}`), "application/merge-patch+json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	equalJSON(t, got, `{"name": "Arbat 1", "ownerId": 5}`)
+
+	got, err = Apply(doc, []byte(`[{"op": "remove", "path": "/ownerId"}]`), "application/json-patch+json; charset=utf-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	equalJSON(t, got, `{"name": "Tverskaya 7"}`)
+}
@@ -0,0 +1,224 @@
+// Package ratelimit limits the requests of each client with token buckets.
+// A client is the identity that an authentication set as ClientKey, such
+// as a verified API token, or else an address. The limits
+// are set per route pattern, and the responses carry the RateLimit-Limit,
+// RateLimit-Remaining and RateLimit-Reset headers, plus Retry-After when a
+// request is refused.
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"location_service_v1/ls_v2/models"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gobuffalo/buffalo"
+)
+
+// ClientKey is the context key of the verified identity of a client, e.g.
+// "token:<id>" of its API token. The buckets of the requests that have it
+// are its own, so the Limiter must run after the authentication that sets
+// it. An Authorization header alone is not trusted: anyone can send one.
+const ClientKey = "ratelimit_client"
+
+// Rule is the Limit of the routes that match Route, a route pattern as
+// declared in the app with an optional method: "GET /api/v1/points" or
+// "/graphql". A pattern ending in "/*" matches the routes under it.
+type Rule struct {
+	Route string
+	Limit Limit
+}
+
+// matches reports whether the rule applies to the route pattern path.
+func (r Rule) matches(method, path string) bool {
+	pattern := r.Route
+	if i := strings.Index(pattern, " "); i >= 0 {
+		if !strings.EqualFold(pattern[:i], method) {
+			return false
+		}
+		pattern = strings.TrimSpace(pattern[i+1:])
+	}
+	if strings.HasSuffix(pattern, "/*") {
+		prefix := strings.TrimSuffix(pattern, "*")
+		return path+"/" == prefix || strings.HasPrefix(path, prefix)
+	}
+	return path == pattern
+}
+
+// ParseRules reads rules written as "<route>=<requests>/<s|m|h>" and
+// separated by commas, e.g. "GET /points=60/m, /api/v1/*=300/m".
+func ParseRules(s string) ([]Rule, error) {
+	rules := []Rule{}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		i := strings.LastIndex(part, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("rate limit %q has no =", part)
+		}
+		route, limit := strings.TrimSpace(part[:i]), strings.TrimSpace(part[i+1:])
+
+		j := strings.Index(limit, "/")
+		if j < 0 {
+			return nil, fmt.Errorf("rate limit %q has no period", part)
+		}
+		n, err := strconv.Atoi(limit[:j])
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("rate limit %q needs a positive number of requests", part)
+		}
+		per, ok := map[string]time.Duration{"s": time.Second, "m": time.Minute, "h": time.Hour}[limit[j+1:]]
+		if !ok {
+			return nil, fmt.Errorf("rate limit %q needs a period of s, m or h", part)
+		}
+		rules = append(rules, Rule{Route: route, Limit: Limit{Requests: n, Per: per}})
+	}
+	return rules, nil
+}
+
+// Limiter is the rate limiting middleware. The first rule that matches a
+// route limits it; the routes no rule matches are not limited.
+type Limiter struct {
+	store Store
+	rules []Rule
+	// ProxyHeader is the header a trusted proxy puts the client address
+	// in, e.g. "X-Forwarded-For". The last address of the header is used,
+	// as the one the proxy appended. Empty means the address of the
+	// connection.
+	ProxyHeader string
+	now         func() time.Time
+}
+
+// New is a
+func New(store Store, rules ...Rule) *Limiter {
+	return &Limiter{
+		store: store,
+		rules: rules,
+		now:   time.Now,
+	}
+}
+
+// Middleware limits the requests of the matched routes. A failure of the
+// store lets the request through.
+func (l *Limiter) Middleware(next buffalo.Handler) buffalo.Handler {
+	return func(c buffalo.Context) error {
+		rule, ok := l.rule(c)
+		if !ok {
+			return next(c)
+		}
+
+		key := rule.Route + "|" + l.client(c)
+		res, err := l.store.Take(key, rule.Limit, l.now())
+		if err != nil {
+			c.Logger().Error(err)
+			return next(c)
+		}
+		if err := l.respond(c, res); err != nil {
+			return err
+		}
+		return next(c)
+	}
+}
+
+// AuthFailures limits the requests that fail their authentication, for
+// the routes whose clients Middleware limits once they are verified. Only
+// a request answered with 401 takes a token from the bucket of its
+// address, and an address whose bucket is empty is refused before its
+// request is authenticated, so that a flood of bad tokens neither goes
+// unlimited nor reaches the database.
+func (l *Limiter) AuthFailures(next buffalo.Handler) buffalo.Handler {
+	return func(c buffalo.Context) error {
+		rule, ok := l.rule(c)
+		if !ok {
+			return next(c)
+		}
+
+		key := rule.Route + "|" + l.Address(c.Request())
+		res, err := l.store.Peek(key, rule.Limit, l.now())
+		if err != nil {
+			c.Logger().Error(err)
+			return next(c)
+		}
+		if !res.Allowed {
+			return l.respond(c, res)
+		}
+
+		err = next(c)
+		var e *models.Error
+		if errors.As(err, &e) && e.Code == models.CodeUnauthorized {
+			if _, err := l.store.Take(key, rule.Limit, l.now()); err != nil {
+				c.Logger().Error(err)
+			}
+		}
+		return err
+	}
+}
+
+// respond sets the rate limit headers of res, and refuses the request when
+// it is not Allowed.
+func (l *Limiter) respond(c buffalo.Context, res Result) error {
+	h := c.Response().Header()
+	h.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+	h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+	if !res.Allowed {
+		retry := ceilSeconds(res.RetryAfter)
+		h.Set("Retry-After", strconv.Itoa(retry))
+		return models.RateLimitedError(fmt.Sprintf("rate limit exceeded, retry in %d s", retry))
+	}
+	return nil
+}
+
+// rule is the rule of the route of the request.
+func (l *Limiter) rule(c buffalo.Context) (Rule, bool) {
+	route, ok := c.Value("current_route").(buffalo.RouteInfo)
+	if !ok {
+		return Rule{}, false
+	}
+	path := route.Path
+	if path != "/" {
+		path = strings.TrimSuffix(path, "/")
+	}
+	for _, rule := range l.rules {
+		if rule.matches(route.Method, path) {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+// client identifies the client of the request: its ClientKey or else
+// its address.
+func (l *Limiter) client(c buffalo.Context) string {
+	if id, ok := c.Value(ClientKey).(string); ok && id != "" {
+		return id
+	}
+	return l.Address(c.Request())
+}
+
+// Address is the client address of r, "ip:<address>", taken from the
+// ProxyHeader when it is set.
+func (l *Limiter) Address(r *http.Request) string {
+	if l.ProxyHeader != "" {
+		if forwarded := r.Header.Get(l.ProxyHeader); forwarded != "" {
+			addrs := strings.Split(forwarded, ",")
+			return "ip:" + strings.TrimSpace(addrs[len(addrs)-1])
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// ceilSeconds is d in whole seconds, rounded up.
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,183 @@
+package ratelimit
+
+import (
+	"errors"
+	"location_service_v1/ls_v2/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gobuffalo/buffalo"
+)
+
+func Test_ParseRules(t *testing.T) {
+	rules, err := ParseRules("GET /points=60/m, /api/v1/*=5/s,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Rule{
+		{Route: "GET /points", Limit: Limit{Requests: 60, Per: time.Minute}},
+		{Route: "/api/v1/*", Limit: Limit{Requests: 5, Per: time.Second}},
+	}
+	if len(rules) != len(want) || rules[0] != want[0] || rules[1] != want[1] {
+		t.Fatalf("got %+v, want %+v", rules, want)
+	}
+
+	for _, s := range []string{"/points", "/points=60", "/points=0/m", "/points=60/d"} {
+		if _, err := ParseRules(s); err == nil {
+			t.Errorf("expected an error for %q", s)
+		}
+	}
+}
+
+func Test_Rule_matches(t *testing.T) {
+	tests := []struct {
+		route  string
+		method string
+		path   string
+		match  bool
+	}{
+		{"GET /points", "GET", "/points", true},
+		{"GET /points", "POST", "/points", false},
+		{"GET /points", "GET", "/points/{point_id}", false},
+		{"/api/v1/*", "PUT", "/api/v1/points/{point_id}", true},
+		{"/api/v1/*", "GET", "/api/v1", true},
+		{"/api/v1/*", "GET", "/api/v10", false},
+	}
+	for _, tt := range tests {
+		if got := (Rule{Route: tt.route}).matches(tt.method, tt.path); got != tt.match {
+			t.Errorf("%s matches %s %s: got %v, want %v", tt.route, tt.method, tt.path, got, tt.match)
+		}
+	}
+}
+
+func Test_Limiter_Middleware(t *testing.T) {
+	l := New(NewMemoryStore(), Rule{Route: "GET /points", Limit: Limit{Requests: 1, Per: time.Minute}})
+	now := time.Unix(1586500000, 0)
+	l.now = func() time.Time { return now }
+
+	app := buffalo.New(buffalo.Options{Env: "test"})
+	app.Use(func(next buffalo.Handler) buffalo.Handler {
+		return func(c buffalo.Context) error {
+			if err := next(c); err != nil {
+				c.Response().WriteHeader(http.StatusTooManyRequests)
+			}
+			return nil
+		}
+	})
+	// a stand-in for the authentication, which verifies one secret
+	app.Use(func(next buffalo.Handler) buffalo.Handler {
+		return func(c buffalo.Context) error {
+			if c.Request().Header.Get("Authorization") == "Bearer ls_secret" {
+				c.Set(ClientKey, "token:1")
+			}
+			return next(c)
+		}
+	})
+	app.Use(l.Middleware)
+	ok := func(c buffalo.Context) error {
+		c.Response().WriteHeader(http.StatusOK)
+		return nil
+	}
+	app.GET("/points", ok)
+	app.GET("/stats", ok)
+
+	get := func(path, auth string) *http.Response {
+		req := httptest.NewRequest("GET", path, nil)
+		req.RemoteAddr = "10.0.0.1:5000"
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, req)
+		return rec.Result()
+	}
+
+	res := get("/points", "")
+	if res.StatusCode != 200 || res.Header.Get("RateLimit-Limit") != "1" || res.Header.Get("RateLimit-Remaining") != "0" {
+		t.Fatalf("first request: got %d %v", res.StatusCode, res.Header)
+	}
+
+	res = get("/points", "")
+	if res.StatusCode != 429 || res.Header.Get("Retry-After") != "60" || res.Header.Get("RateLimit-Reset") != "60" {
+		t.Fatalf("second request: got %d %v", res.StatusCode, res.Header)
+	}
+
+	// a verified token is a client of its own, an unverified one is not
+	if res := get("/points", "Bearer ls_secret"); res.StatusCode != 200 {
+		t.Errorf("with a token: got %d", res.StatusCode)
+	}
+	if res := get("/points", "Bearer ls_made_up"); res.StatusCode != 429 {
+		t.Errorf("with an unverified token: got %d", res.StatusCode)
+	}
+
+	// unmatched routes are not limited
+	if res := get("/stats", ""); res.StatusCode != 200 || res.Header.Get("RateLimit-Limit") != "" {
+		t.Errorf("unmatched route: got %d %v", res.StatusCode, res.Header)
+	}
+}
+
+func Test_Limiter_AuthFailures(t *testing.T) {
+	l := New(NewMemoryStore(), Rule{Route: "/api/v1/*", Limit: Limit{Requests: 2, Per: time.Minute}})
+	now := time.Unix(1586500000, 0)
+	l.now = func() time.Time { return now }
+
+	app := buffalo.New(buffalo.Options{Env: "test"})
+	app.Use(func(next buffalo.Handler) buffalo.Handler {
+		return func(c buffalo.Context) error {
+			var e *models.Error
+			if err := next(c); errors.As(err, &e) && e.Code == models.CodeUnauthorized {
+				c.Response().WriteHeader(http.StatusUnauthorized)
+			} else if err != nil {
+				c.Response().WriteHeader(http.StatusTooManyRequests)
+			}
+			return nil
+		}
+	})
+	// the API group: its address is only limited on failed
+	// authentications, its tokens once they are verified
+	authenticated := 0
+	app.Use(l.AuthFailures, func(next buffalo.Handler) buffalo.Handler {
+		return func(c buffalo.Context) error {
+			authenticated++
+			if c.Request().Header.Get("Authorization") != "Bearer ls_secret" {
+				return models.UnauthorizedError("invalid bearer token")
+			}
+			c.Set(ClientKey, "token:1")
+			return next(c)
+		}
+	}, l.Middleware)
+	app.GET("/api/v1/points", func(c buffalo.Context) error {
+		c.Response().WriteHeader(http.StatusOK)
+		return nil
+	})
+
+	get := func(addr, auth string) int {
+		req := httptest.NewRequest("GET", "/api/v1/points", nil)
+		req.RemoteAddr = addr
+		req.Header.Set("Authorization", auth)
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	for i, want := range []int{401, 401, 429, 429} {
+		if got := get("10.0.0.1:5000", "Bearer ls_made_up"); got != want {
+			t.Errorf("bad token %d: got %d, want %d", i, got, want)
+		}
+	}
+	if authenticated != 2 {
+		t.Errorf("the refused requests must not be authenticated, got %d authentications", authenticated)
+	}
+
+	// the verified requests take from the bucket of the token only
+	for i, want := range []int{200, 200, 429} {
+		if got := get("10.0.0.2:5000", "Bearer ls_secret"); got != want {
+			t.Errorf("token %d: got %d, want %d", i, got, want)
+		}
+	}
+	if got := get("10.0.0.2:5000", "Bearer ls_made_up"); got != 401 {
+		t.Errorf("bad token after verified ones: got %d", got)
+	}
+}
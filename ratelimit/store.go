@@ -0,0 +1,139 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit is a token bucket: it holds up to Requests tokens and refills them
+// evenly over Per. Each request takes a token, so a client may burst
+// Requests requests and then keep to Requests per Per.
+type Limit struct {
+	Requests int
+	Per      time.Duration
+}
+
+// PerMinute is a Limit of n requests a minute.
+func PerMinute(n int) Limit {
+	return Limit{Requests: n, Per: time.Minute}
+}
+
+// rate is the number of tokens the bucket gains per second.
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Per.Seconds()
+}
+
+// Result is the state of a bucket after a request took, or failed to
+// take, a token from it.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until the next token, when not Allowed.
+	RetryAfter time.Duration
+}
+
+// Store keeps the buckets of the clients. MemoryStore keeps them in the
+// process; a store shared by the instances of the app, e.g. in Redis,
+// limits a client across all of them.
+type Store interface {
+	// Take takes a token from the bucket of key with the limit.
+	Take(key string, limit Limit, now time.Time) (Result, error)
+	// Peek is the state of the bucket of key, without taking a token.
+	Peek(key string, limit Limit, now time.Time) (Result, error)
+}
+
+// bucket is a token bucket as of updated.
+type bucket struct {
+	limit   Limit
+	tokens  float64
+	updated time.Time
+}
+
+// refill is the number of tokens in the bucket at now.
+func (b *bucket) refill(now time.Time) float64 {
+	return math.Min(float64(b.limit.Requests), b.tokens+now.Sub(b.updated).Seconds()*b.limit.rate())
+}
+
+// sweepInterval is how often MemoryStore forgets the full buckets.
+const sweepInterval = time.Minute
+
+// MemoryStore is a Store in the memory of the process.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	swept   time.Time
+}
+
+// NewMemoryStore is a
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: map[string]*bucket{},
+	}
+}
+
+// Take takes a token from the bucket of key, refilled for the time since
+// its last request.
+func (s *MemoryStore) Take(key string, limit Limit, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.swept) > sweepInterval {
+		s.sweep(now)
+	}
+
+	b, ok := s.buckets[key]
+	if !ok || b.limit != limit {
+		b = &bucket{limit: limit, tokens: float64(limit.Requests), updated: now}
+		s.buckets[key] = b
+	}
+	b.tokens = b.refill(now)
+	b.updated = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	return newResult(allowed, b.tokens, limit), nil
+}
+
+// Peek is the state of the bucket of key at now. Allowed tells whether a
+// request would get a token.
+func (s *MemoryStore) Peek(key string, limit Limit, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens := float64(limit.Requests)
+	if b, ok := s.buckets[key]; ok && b.limit == limit {
+		tokens = b.refill(now)
+	}
+	return newResult(tokens >= 1, tokens, limit), nil
+}
+
+// newResult is the Result of a bucket of the limit left with tokens.
+func newResult(allowed bool, tokens float64, limit Limit) Result {
+	res := Result{Allowed: allowed, Limit: limit.Requests, Remaining: int(tokens)}
+	if !allowed {
+		res.RetryAfter = seconds((1 - tokens) / limit.rate())
+	}
+	res.Reset = seconds((float64(limit.Requests) - tokens) / limit.rate())
+	return res
+}
+
+// sweep forgets the buckets that have refilled: a new bucket is the same.
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		if b.refill(now) >= float64(b.limit.Requests) {
+			delete(s.buckets, key)
+		}
+	}
+	s.swept = now
+}
+
+// seconds is a duration of s seconds.
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
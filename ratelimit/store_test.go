@@ -0,0 +1,72 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_MemoryStore_Take(t *testing.T) {
+	s := NewMemoryStore()
+	limit := Limit{Requests: 2, Per: 10 * time.Second}
+	now := time.Unix(1586500000, 0)
+
+	for i, want := range []int{1, 0} {
+		res, err := s.Take("a", limit, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !res.Allowed || res.Remaining != want {
+			t.Fatalf("request %d: got %+v, want allowed with %d remaining", i, res, want)
+		}
+	}
+
+	res, _ := s.Take("a", limit, now)
+	if res.Allowed || res.RetryAfter != 5*time.Second || res.Reset != 10*time.Second {
+		t.Fatalf("got %+v, want refused for 5s", res)
+	}
+
+	// other keys have buckets of their own
+	if res, _ := s.Take("b", limit, now); !res.Allowed {
+		t.Fatalf("got %+v for another key", res)
+	}
+
+	// one token is back after 5s
+	res, _ = s.Take("a", limit, now.Add(5*time.Second))
+	if !res.Allowed || res.Remaining != 0 {
+		t.Fatalf("got %+v after 5s", res)
+	}
+}
+
+func Test_MemoryStore_Peek(t *testing.T) {
+	s := NewMemoryStore()
+	limit := Limit{Requests: 1, Per: 10 * time.Second}
+	now := time.Unix(1586500000, 0)
+
+	for i := 0; i < 2; i++ {
+		if res, _ := s.Peek("a", limit, now); !res.Allowed || res.Remaining != 1 {
+			t.Fatalf("peek %d: got %+v", i, res)
+		}
+	}
+	s.Take("a", limit, now)
+	if res, _ := s.Peek("a", limit, now); res.Allowed || res.RetryAfter != 10*time.Second {
+		t.Fatalf("got %+v after a take", res)
+	}
+}
+
+func Test_MemoryStore_Sweep(t *testing.T) {
+	s := NewMemoryStore()
+	limit := PerMinute(60)
+	now := time.Unix(1586500000, 0)
+
+	s.Take("a", limit, now)
+	s.Take("b", limit, now.Add(sweepInterval))
+	s.Take("c", limit, now.Add(2*sweepInterval))
+
+	// "a" and "b" have refilled by the third request
+	if _, ok := s.buckets["a"]; ok {
+		t.Error("expected the full bucket to be swept")
+	}
+	if len(s.buckets) != 1 {
+		t.Errorf("got %d buckets, want 1", len(s.buckets))
+	}
+}
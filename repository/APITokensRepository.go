@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"location_service_v1/ls_v2/models"
+	"time"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/pop"
+)
+
+// APITokensRepository is a
+type APITokensRepository struct {
+}
+
+// NewAPITokensRepository is a
+func NewAPITokensRepository() *APITokensRepository {
+	return &APITokensRepository{}
+}
+
+// Authenticate finds the unrevoked APIToken of the bearer secret and
+// records its use. Unknown and revoked secrets are answered with 401.
+func (p *APITokensRepository) Authenticate(c buffalo.Context, secret string) (*models.APIToken, error) {
+	// Get the DB connection from the context
+	tx, ok := c.Value("tx").(*pop.Connection)
+	if !ok {
+		return nil, errNoTransaction
+	}
+
+	token := &models.APIToken{}
+	err := tx.Where("token_hash = ? AND revoked_at IS NULL", models.HashToken(secret)).First(token)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, models.UnauthorizedError("invalid token")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.RawQuery("UPDATE api_tokens SET last_used_at = ? WHERE id = ?", time.Now(), token.ID).Exec()
+	if err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
@@ -1,13 +1,12 @@
 package repository
 
 import (
-	"fmt"
 	"location_service_v1/ls_v2/models"
-	"net/http"
 
 	"github.com/gobuffalo/buffalo"
 	"github.com/gobuffalo/pop"
 	"github.com/gobuffalo/validate"
+	"github.com/gofrs/uuid"
 )
 
 // CompaniesRepository is a
@@ -19,13 +18,26 @@ func NewCompaniesRepository() *CompaniesRepository {
 	return &CompaniesRepository{}
 }
 
+// companyFilters are the query parameters accepted by List.
+var companyFilters = map[string]filter{
+	"name":          contains("name"),
+	"created_after": after("created_at"),
+}
+
+// companySortColumns are the fields List can be sorted by.
+var companySortColumns = map[string]string{
+	"name":       "name",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
 // List gets all Companies. This function is mapped to the path
 // GET /companies
 func (p *CompaniesRepository) List(c buffalo.Context) (*models.Companies, *pop.Query, error) {
 	// Get the DB connection from the context
 	tx, ok := c.Value("tx").(*pop.Connection)
 	if !ok {
-		return nil, nil, fmt.Errorf("no transaction found")
+		return nil, nil, errNoTransaction
 	}
 
 	companies := &models.Companies{}
@@ -34,41 +46,189 @@ func (p *CompaniesRepository) List(c buffalo.Context) (*models.Companies, *pop.Q
 	// Default values are "page=1" and "per_page=20".
 	q := tx.PaginateFromParams(c.Params())
 
-	// Retrieve all Companies from the DB
-
-	// //with eager points
-	// if err := q.Eager().All(companies); err != nil {
-	// 	return nil, nil, err
-	// }
+	// Whitelisted filters and "sort" from the query string.
+	q, err := applyFilters(q, c.Params(), companyFilters)
+	if err != nil {
+		return nil, nil, models.BadRequestError(err)
+	}
+	if q, err = applySort(q, c.Param("sort"), companySortColumns, "-created_at"); err != nil {
+		return nil, nil, models.BadRequestError(err)
+	}
+	if q, err = selectFields(q, &models.Company{}, c.Param("fields"), "id", "created_at"); err != nil {
+		return nil, nil, models.BadRequestError(err)
+	}
 
-	// just companies
+	// Retrieve all Companies from the DB
 	if err := q.All(companies); err != nil {
 		return nil, nil, err
 	}
 
+	// Param "include=points" loads the points of the page in one query
+	if err := p.include(c, tx, *companies); err != nil {
+		return nil, nil, err
+	}
+
 	return companies, q, nil
 }
 
+// All gets every Company ordered by name, e.g. for select boxes.
+func (p *CompaniesRepository) All(c buffalo.Context) (*models.Companies, error) {
+	// Get the DB connection from the context
+	tx, ok := c.Value("tx").(*pop.Connection)
+	if !ok {
+		return nil, errNoTransaction
+	}
+
+	companies := &models.Companies{}
+	if err := tx.Order("name").All(companies); err != nil {
+		return nil, err
+	}
+
+	return companies, nil
+}
+
+// Scroll gets Companies page by page with the "after", "before" and "limit"
+// keyset cursors, newest first. This function is mapped to the path
+// GET /companies for JSON and XML clients
+func (p *CompaniesRepository) Scroll(c buffalo.Context) (*models.Companies, *CursorPage, error) {
+	// Get the DB connection from the context
+	tx, ok := c.Value("tx").(*pop.Connection)
+	if !ok {
+		return nil, nil, errNoTransaction
+	}
+
+	companies := &models.Companies{}
+
+	window, err := newCursorWindow(c.Params())
+	if err != nil {
+		return nil, nil, models.BadRequestError(err)
+	}
+
+	q, err := applyFilters(tx.Q(), c.Params(), companyFilters)
+	if err != nil {
+		return nil, nil, models.BadRequestError(err)
+	}
+	if q, err = selectFields(q, &models.Company{}, c.Param("fields"), "id", "created_at"); err != nil {
+		return nil, nil, models.BadRequestError(err)
+	}
+
+	if err := window.apply(q).All(companies); err != nil {
+		return nil, nil, err
+	}
+
+	if err := p.include(c, tx, *companies); err != nil {
+		return nil, nil, err
+	}
+
+	return companies, window.page(companies), nil
+}
+
 // Show gets the data for one Point. This function is mapped to
 // the path GET /companies/{company_id}
 func (p *CompaniesRepository) Show(c buffalo.Context) (*models.Company, error) {
 	// Get the DB connection from the context
 	tx, ok := c.Value("tx").(*pop.Connection)
 	if !ok {
-		return nil, fmt.Errorf("no transaction found")
+		return nil, errNoTransaction
 	}
 
 	// Allocate an empty Company
 	company := &models.Company{}
 
+	// Param "fields" limits the columns read from the DB
+	q, err := selectFields(tx.Q(), &models.Company{}, c.Param("fields"), "id", "created_at", "version")
+	if err != nil {
+		return nil, models.BadRequestError(err)
+	}
+
 	// To find the Company the parameter company_id is used.
-	if err := tx.Find(company, c.Param("company_id")); err != nil {
-		return nil, c.Error(http.StatusNotFound, err)
+	if err := q.Find(company, c.Param("company_id")); err != nil {
+		return nil, models.NotFoundError("company", err)
+	}
+
+	companies := models.Companies{*company}
+	if err := p.include(c, tx, companies); err != nil {
+		return nil, err
+	}
+
+	return &companies[0], nil
+}
+
+// include loads the associations named by the "include" parameter.
+func (p *CompaniesRepository) include(c buffalo.Context, tx *pop.Connection, companies []models.Company) error {
+	includes, err := parseIncludes(c.Param("include"), "points")
+	if err != nil {
+		return models.BadRequestError(err)
+	}
+	if includes["points"] {
+		return loadCompanyPoints(tx, companies)
+	}
+	return nil
+}
+
+// LoadPoints sets the Points of the companies with one query.
+func (p *CompaniesRepository) LoadPoints(c buffalo.Context, companies []models.Company) error {
+	// Get the DB connection from the context
+	tx, ok := c.Value("tx").(*pop.Connection)
+	if !ok {
+		return errNoTransaction
+	}
+	return loadCompanyPoints(tx, companies)
+}
+
+// Find gets the Company of the company_id of the path, ignoring the
+// "fields" and "include" parameters meant for nested resources.
+func (p *CompaniesRepository) Find(c buffalo.Context) (*models.Company, error) {
+	// Get the DB connection from the context
+	tx, ok := c.Value("tx").(*pop.Connection)
+	if !ok {
+		return nil, errNoTransaction
 	}
 
+	// Allocate an empty Company
+	company := &models.Company{}
+
+	if err := tx.Find(company, c.Param("company_id")); err != nil {
+		return nil, models.NotFoundError("company", err)
+	}
 	return company, nil
 }
 
+// CountPoints counts the Points of the company_id of the path, e.g. to
+// warn how many points a destroy would affect.
+func (p *CompaniesRepository) CountPoints(c buffalo.Context) (int, error) {
+	// Get the DB connection from the context
+	tx, ok := c.Value("tx").(*pop.Connection)
+	if !ok {
+		return 0, errNoTransaction
+	}
+
+	return tx.Where("company_id = ?", c.Param("company_id")).Count(&models.Point{})
+}
+
+// ReleasePoints takes the Points of the company_id of the path out of the
+// company, e.g. when it is destroyed. Each point is updated on its own, as
+// an edit of it would be.
+func (p *CompaniesRepository) ReleasePoints(c buffalo.Context) (*models.Points, error) {
+	// Get the DB connection from the context
+	tx, ok := c.Value("tx").(*pop.Connection)
+	if !ok {
+		return nil, errNoTransaction
+	}
+
+	points := &models.Points{}
+	if err := tx.Where("company_id = ?", c.Param("company_id")).All(points); err != nil {
+		return nil, err
+	}
+	for i := range *points {
+		(*points)[i].CompanyID = uuid.Nil
+		if err := tx.Update(&(*points)[i]); err != nil {
+			return nil, err
+		}
+	}
+	return points, nil
+}
+
 // New renders the form for creating a new Company.
 // This function is mapped to the path GET /points/new
 func (p *CompaniesRepository) New(c buffalo.Context) *models.Company {
@@ -89,7 +249,7 @@ func (p *CompaniesRepository) Create(c buffalo.Context) (*validate.Errors, *mode
 	// Get the DB connection from the context
 	tx, ok := c.Value("tx").(*pop.Connection)
 	if !ok {
-		return nil, nil, fmt.Errorf("no transaction found")
+		return nil, nil, errNoTransaction
 	}
 
 	// Validate the data from the html form
@@ -101,38 +261,65 @@ func (p *CompaniesRepository) Create(c buffalo.Context) (*validate.Errors, *mode
 	return created, company, nil
 }
 
+// Insert validates and adds an already filled Company to the DB, e.g. one
+// decoded from an API request.
+func (p *CompaniesRepository) Insert(c buffalo.Context, company *models.Company) (*validate.Errors, error) {
+	// Get the DB connection from the context
+	tx, ok := c.Value("tx").(*pop.Connection)
+	if !ok {
+		return nil, errNoTransaction
+	}
+
+	return tx.ValidateAndCreate(company)
+}
+
+// Save validates and updates an already filled Company in the DB.
+func (p *CompaniesRepository) Save(c buffalo.Context, company *models.Company) (*validate.Errors, error) {
+	// Get the DB connection from the context
+	tx, ok := c.Value("tx").(*pop.Connection)
+	if !ok {
+		return nil, errNoTransaction
+	}
+
+	return tx.ValidateAndUpdate(company)
+}
+
 // Edit renders a edit form for a Company. This function is
 // mapped to the path GET /companies/{company_id}/edit
 func (p *CompaniesRepository) Edit(c buffalo.Context) (*models.Company, error) {
 	// Get the DB connection from the context
 	tx, ok := c.Value("tx").(*pop.Connection)
 	if !ok {
-		return nil, fmt.Errorf("no transaction found")
+		return nil, errNoTransaction
 	}
 
 	// Allocate an empty Company
 	company := &models.Company{}
 
 	if err := tx.Find(company, c.Param("company_id")); err != nil {
-		return nil, c.Error(http.StatusNotFound, err)
+		return nil, models.NotFoundError("company", err)
 	}
 	return company, nil
 }
 
 // Update changes a Company in the DB. This function is mapped to
 // the path PUT /companies/{company_id}
+// A stale version is a conflict that returns the current Company.
 func (p *CompaniesRepository) Update(c buffalo.Context) (*validate.Errors, *models.Company, error) {
 	// Get the DB connection from the context
 	tx, ok := c.Value("tx").(*pop.Connection)
 	if !ok {
-		return nil, nil, fmt.Errorf("no transaction found")
+		return nil, nil, errNoTransaction
 	}
 
 	// Allocate an empty Company
 	company := &models.Company{}
 
-	if err := tx.Find(company, c.Param("company_id")); err != nil {
-		return nil, nil, c.Error(http.StatusNotFound, err)
+	if err := findForChange(tx, company, c.Param("company_id")); err != nil {
+		return nil, nil, models.NotFoundError("company", err)
+	}
+	if err := checkVersion(c, "company", company.Version); err != nil {
+		return nil, company, err
 	}
 
 	// Bind Company to the html form elements
@@ -148,21 +335,56 @@ func (p *CompaniesRepository) Update(c buffalo.Context) (*validate.Errors, *mode
 	return updated, company, nil
 }
 
+// Patch changes only the fields of a Company that the JSON Merge Patch or
+// JSON Patch body sends. This function is mapped to the path
+// PATCH /companies/{company_id}
+func (p *CompaniesRepository) Patch(c buffalo.Context) (*validate.Errors, *models.Company, error) {
+	// Get the DB connection from the context
+	tx, ok := c.Value("tx").(*pop.Connection)
+	if !ok {
+		return nil, nil, errNoTransaction
+	}
+
+	// Allocate an empty Company
+	company := &models.Company{}
+
+	if err := findForChange(tx, company, c.Param("company_id")); err != nil {
+		return nil, nil, models.NotFoundError("company", err)
+	}
+	if err := checkVersion(c, "company", company.Version); err != nil {
+		return nil, nil, err
+	}
+
+	if err := patchModel(c, company); err != nil {
+		return nil, nil, err
+	}
+
+	updated, err := tx.ValidateAndUpdate(company)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return updated, company, nil
+}
+
 // Destroy deletes a Company from the DB. This function is mapped
 // to the path DELETE /companies/{company_id}
 func (p *CompaniesRepository) Destroy(c buffalo.Context) (*models.Company, error) {
 	// Get the DB connection from the context
 	tx, ok := c.Value("tx").(*pop.Connection)
 	if !ok {
-		return nil, fmt.Errorf("no transaction found")
+		return nil, errNoTransaction
 	}
 
 	// Allocate an empty Company
 	company := &models.Company{}
 
 	// To find the Point the parameter company_id is used.
-	if err := tx.Find(company, c.Param("company_id")); err != nil {
-		return nil, c.Error(http.StatusNotFound, err)
+	if err := findForChange(tx, company, c.Param("company_id")); err != nil {
+		return nil, models.NotFoundError("company", err)
+	}
+	if err := checkVersion(c, "company", company.Version); err != nil {
+		return nil, err
 	}
 
 	if err := tx.Destroy(company); err != nil {
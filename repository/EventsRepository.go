@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"encoding/json"
+	"location_service_v1/ls_v2/models"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/pop"
+)
+
+// maxNotifyPayload is a little under the 8000 bytes PostgreSQL allows in
+// the payload of a NOTIFY.
+const maxNotifyPayload = 7900
+
+// EventsRepository publishes the live events with PostgreSQL NOTIFY, so
+// that the /events streams of every instance get them.
+type EventsRepository struct {
+}
+
+// NewEventsRepository is a
+func NewEventsRepository() *EventsRepository {
+	return &EventsRepository{}
+}
+
+// Publish sends the event when the transaction of c commits. Nothing is
+// sent for a rolled back change.
+func (p *EventsRepository) Publish(c buffalo.Context, event *models.LiveEvent) error {
+	// Get the DB connection from the context
+	tx, ok := c.Value("tx").(*pop.Connection)
+	if !ok {
+		return errNoTransaction
+	}
+	return notifyLiveEvent(tx, event)
+}
+
+// PublishNow sends the event at once, outside of the transaction of the
+// request, e.g. the progress of an import that has not committed yet.
+func (p *EventsRepository) PublishNow(event *models.LiveEvent) error {
+	return notifyLiveEvent(models.DB, event)
+}
+
+// notifyLiveEvent sends the event on tx. The data of an event too large
+// for a NOTIFY is left out; streams can still reload the record.
+func notifyLiveEvent(tx *pop.Connection, event *models.LiveEvent) error {
+	b, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if len(b) > maxNotifyPayload {
+		small := *event
+		small.Data = nil
+		if b, err = json.Marshal(small); err != nil {
+			return err
+		}
+	}
+	return tx.RawQuery("SELECT pg_notify(?, ?)", models.LiveEventsChannel, string(b)).Exec()
+}
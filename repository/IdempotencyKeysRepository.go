@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"location_service_v1/ls_v2/models"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// idempotencyBeginAttempts bounds the retries of Begin when other requests
+// with the same key insert or take it over at the same time.
+const idempotencyBeginAttempts = 3
+
+// idempotencySweepEvery is how often Begin deletes the expired keys.
+const idempotencySweepEvery = time.Minute
+
+// IdempotencyKeysRepository keeps the Idempotency-Keys outside of the
+// request transaction: the key of a request is taken before the handler
+// runs and its response is stored after the transaction commits.
+type IdempotencyKeysRepository struct {
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+// NewIdempotencyKeysRepository is a
+func NewIdempotencyKeysRepository() *IdempotencyKeysRepository {
+	return &IdempotencyKeysRepository{}
+}
+
+// Begin takes the key of the client for a request. started is true when
+// the request is the first one with the key, or the key had expired or was
+// abandoned, and the request has to run. Otherwise the stored key is
+// returned, with the response to replay unless it is still Running.
+func (p *IdempotencyKeysRepository) Begin(key *models.IdempotencyKey) (*models.IdempotencyKey, bool, error) {
+	p.sweep(key.CreatedAt)
+
+	for i := 0; i < idempotencyBeginAttempts; i++ {
+		key.ID = uuid.Must(uuid.NewV4())
+		key.UpdatedAt = key.CreatedAt
+		n, err := models.DB.RawQuery(`INSERT INTO idempotency_keys
+			(id, client, key, route, request_hash, status, headers, body, expires_at, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, 0, '{}', '', ?, ?, ?)
+			ON CONFLICT (client, key) DO NOTHING`,
+			key.ID, key.Client, key.Key, key.Route, key.RequestHash, key.ExpiresAt, key.CreatedAt, key.UpdatedAt).ExecWithCount()
+		if err != nil {
+			return nil, false, err
+		}
+		if n == 1 {
+			return key, true, nil
+		}
+
+		stored := &models.IdempotencyKey{}
+		err = models.DB.Where("client = ? AND key = ?", key.Client, key.Key).First(stored)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		if !stored.Reusable(key.CreatedAt) {
+			return stored, false, nil
+		}
+
+		// Delete the key only as it was read, so that a response stored in
+		// the meantime is kept, then try to insert it again.
+		err = models.DB.RawQuery("DELETE FROM idempotency_keys WHERE id = ? AND status = ? AND updated_at = ?",
+			stored.ID, stored.Status, stored.UpdatedAt).Exec()
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	return nil, false, models.ConflictError("the Idempotency-Key is in use by another request")
+}
+
+// Finish stores the response of the request that took the key.
+func (p *IdempotencyKeysRepository) Finish(key *models.IdempotencyKey) error {
+	key.UpdatedAt = time.Now()
+	return models.DB.RawQuery("UPDATE idempotency_keys SET status = ?, headers = ?, body = ?, updated_at = ? WHERE id = ?",
+		key.Status, key.Headers, key.Body, key.UpdatedAt, key.ID).Exec()
+}
+
+// Release deletes the key of a request that failed, so that the client can
+// retry with it.
+func (p *IdempotencyKeysRepository) Release(key *models.IdempotencyKey) error {
+	return models.DB.RawQuery("DELETE FROM idempotency_keys WHERE id = ?", key.ID).Exec()
+}
+
+// sweep deletes the expired keys, at most every idempotencySweepEvery. A
+// failure is left for the next sweep.
+func (p *IdempotencyKeysRepository) sweep(now time.Time) {
+	p.mu.Lock()
+	if now.Sub(p.lastSweep) < idempotencySweepEvery {
+		p.mu.Unlock()
+		return
+	}
+	p.lastSweep = now
+	p.mu.Unlock()
+
+	_ = models.DB.RawQuery("DELETE FROM idempotency_keys WHERE expires_at < ?", now).Exec()
+}
@@ -6,10 +6,15 @@ import (
 	"io/ioutil"
 	"location_service_v1/ls_v2/models"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gobuffalo/buffalo"
 	"github.com/gobuffalo/pop"
+	"github.com/gobuffalo/pop/columns"
 	"github.com/gobuffalo/validate"
+	"github.com/gofrs/uuid"
 )
 
 // PointsRepository is a
@@ -21,13 +26,42 @@ func NewPointsRepository() *PointsRepository {
 	return &PointsRepository{}
 }
 
+// pointFilters are the query parameters accepted by List.
+var pointFilters = map[string]filter{
+	"company_id":    equalsUUID("company_id"),
+	"city":          equalsFold("citi_name"),
+	"owner_id":      equalsInt("owner_id"),
+	"name":          contains("name"),
+	"created_after": after("created_at"),
+	"problem":       oneOf(pointProblems),
+}
+
+// pointProblems are the data-quality problems of a point, as accepted by the
+// "problem" filter and counted on the dashboard.
+var pointProblems = map[string]string{
+	"no_company": "NOT EXISTS (SELECT 1 FROM companies WHERE companies.id = points.company_id)",
+	"no_address": "COALESCE(points.address, '') = ''",
+	"no_city":    "COALESCE(points.citi_name, '') = ''",
+	"duplicate_point_id": `points.point_id <> 0 AND EXISTS (SELECT 1 FROM points AS dup
+		WHERE dup.company_id = points.company_id AND dup.point_id = points.point_id AND dup.id <> points.id)`,
+}
+
+// pointSortColumns are the fields List can be sorted by.
+var pointSortColumns = map[string]string{
+	"name":       "name",
+	"city":       "citi_name",
+	"point_id":   "point_id",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
 // List gets all Points. This function is mapped to the path
 // GET /points
 func (p *PointsRepository) List(c buffalo.Context) (*models.Points, *pop.Query, error) {
 	// Get the DB connection from the context
 	tx, ok := c.Value("tx").(*pop.Connection)
 	if !ok {
-		return nil, nil, fmt.Errorf("no transaction found")
+		return nil, nil, errNoTransaction
 	}
 
 	points := &models.Points{}
@@ -36,48 +70,376 @@ func (p *PointsRepository) List(c buffalo.Context) (*models.Points, *pop.Query,
 	// Default values are "page=1" and "per_page=20".
 	q := tx.PaginateFromParams(c.Params())
 
-	// // Retrieve all Points from the DB
-	// if err := q.Eager().All(points); err != nil {
-	// 	return nil, nil, err
-	// }
+	// Whitelisted filters and "sort" from the query string.
+	q, err := filterPoints(q, c.Params())
+	if err != nil {
+		return nil, nil, models.BadRequestError(err)
+	}
+	if q, err = applySort(q, c.Param("sort"), pointSortColumns, "-created_at"); err != nil {
+		return nil, nil, models.BadRequestError(err)
+	}
+	if q, err = selectFields(q, &models.Point{}, c.Param("fields"), "id", "created_at", "company_id"); err != nil {
+		return nil, nil, models.BadRequestError(err)
+	}
 
 	// Retrieve all Points from the DB
 	if err := q.All(points); err != nil {
 		return nil, nil, err
 	}
 
+	// Param "include=company" loads the companies of the page in one query
+	if err := p.include(c, tx, *points); err != nil {
+		return nil, nil, err
+	}
+
 	return points, q, nil
 }
 
+// Scroll gets Points page by page with the "after", "before" and "limit"
+// keyset cursors, newest first. This function is mapped to the path
+// GET /points for JSON and XML clients
+func (p *PointsRepository) Scroll(c buffalo.Context) (*models.Points, *CursorPage, error) {
+	// Get the DB connection from the context
+	tx, ok := c.Value("tx").(*pop.Connection)
+	if !ok {
+		return nil, nil, errNoTransaction
+	}
+
+	points := &models.Points{}
+
+	window, err := newCursorWindow(c.Params())
+	if err != nil {
+		return nil, nil, models.BadRequestError(err)
+	}
+
+	q, err := filterPoints(tx.Q(), c.Params())
+	if err != nil {
+		return nil, nil, models.BadRequestError(err)
+	}
+	if q, err = selectFields(q, &models.Point{}, c.Param("fields"), "id", "created_at", "company_id"); err != nil {
+		return nil, nil, models.BadRequestError(err)
+	}
+
+	if err := window.apply(q).All(points); err != nil {
+		return nil, nil, err
+	}
+
+	if err := p.include(c, tx, *points); err != nil {
+		return nil, nil, err
+	}
+
+	return points, window.page(points), nil
+}
+
+// filterPoints applies the whitelisted filters of List and Scroll. Params
+// "parcel" (LxWxH in millimetres) and "weight_g" keep only the points that
+// can accept the parcel.
+func filterPoints(q *pop.Query, params buffalo.ParamValues) (*pop.Query, error) {
+	q, err := applyFilters(q, params, pointFilters)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.Get("parcel") != "" || params.Get("weight_g") != "" {
+		parcel, err := models.ParseParcel(params.Get("parcel"), params.Get("weight_g"))
+		if err != nil {
+			return nil, err
+		}
+		q = parcelFits(q, parcel)
+	}
+
+	return q, nil
+}
+
+// Search finds Points by the words of the "q" parameter in their name,
+// address, city and description. This function is mapped to the path
+// GET /points/search
+func (p *PointsRepository) Search(c buffalo.Context) (*models.PointSearchResults, *pop.Query, error) {
+	// Get the DB connection from the context
+	tx, ok := c.Value("tx").(*pop.Connection)
+	if !ok {
+		return nil, nil, errNoTransaction
+	}
+
+	results := &models.PointSearchResults{}
+
+	query := prefixQuery(c.Param("q"))
+	if query == "" {
+		return results, tx.PaginateFromParams(c.Params()), nil
+	}
+
+	sql := fmt.Sprintf(`SELECT %s,
+		ts_rank(points.search_vector, query) AS rank,
+		ts_headline('russian', concat_ws(' / ', points.name, points.address, points.citi_name, points.out_description),
+			query, ?) AS snippet
+		FROM points, (SELECT to_tsquery('russian', ?) || to_tsquery('english', ?) AS query) q
+		WHERE points.search_vector @@ query
+		ORDER BY rank DESC, points.id`,
+		columns.ForStruct(&models.Point{}, "points").Readable().SelectString())
+	options := fmt.Sprintf("StartSel=%s, StopSel=%s, MaxFragments=2", highlightStart, highlightStop)
+
+	// Paginate results. Params "page" and "per_page" control pagination.
+	q := tx.RawQuery(sql, options, query, query).PaginateFromParams(c.Params())
+	if err := q.All(results); err != nil {
+		return nil, nil, err
+	}
+
+	for i := range *results {
+		(*results)[i].Snippet = highlight((*results)[i].Snippet)
+	}
+
+	return results, q, nil
+}
+
+// autocompleteColumns maps the "type" parameter of Autocomplete to a column.
+var autocompleteColumns = map[string]string{
+	"city":    "citi_name",
+	"address": "address",
+	"point":   "name",
+}
+
+// Autocomplete suggests cities, addresses or point names for the "q"
+// parameter. This function is mapped to the path GET /autocomplete
+func (p *PointsRepository) Autocomplete(c buffalo.Context) (*models.Suggestions, error) {
+	// Get the DB connection from the context
+	tx, ok := c.Value("tx").(*pop.Connection)
+	if !ok {
+		return nil, errNoTransaction
+	}
+
+	kind := c.Param("type")
+	if kind == "" {
+		kind = "city"
+	}
+	column, ok := autocompleteColumns[kind]
+	if !ok {
+		return nil, models.BadRequestError(fmt.Errorf("type must be city, address or point"))
+	}
+
+	limit := 10
+	if l, err := strconv.Atoi(c.Param("limit")); err == nil && l > 0 && l <= 50 {
+		limit = l
+	}
+
+	suggestions := &models.Suggestions{}
+
+	text := strings.TrimSpace(c.Param("q"))
+	if text == "" {
+		return suggestions, nil
+	}
+	prefix := strings.ToLower(escapeLike(text)) + "%"
+
+	// Trigrams need at least three characters, shorter input is matched as
+	// a prefix through the lower(column) text_pattern_ops index.
+	where, match := "lower("+column+") LIKE ?", prefix
+	if len([]rune(text)) >= 3 {
+		where, match = column+" ILIKE ?", "%"+escapeLike(text)+"%"
+	}
+
+	sql := fmt.Sprintf(`SELECT %[1]s AS value, COUNT(*) AS points
+		FROM points
+		WHERE %[2]s
+		GROUP BY %[1]s
+		ORDER BY lower(%[1]s) LIKE ? DESC, similarity(%[1]s, ?) DESC, points DESC, value
+		LIMIT ?`, column, where)
+
+	if err := tx.RawQuery(sql, match, prefix, text, limit).All(suggestions); err != nil {
+		return nil, err
+	}
+
+	return suggestions, nil
+}
+
 // Show gets the data for one Point. This function is mapped to
 // the path GET /points/{point_id}
 func (p *PointsRepository) Show(c buffalo.Context) (*models.Point, error) {
 	// Get the DB connection from the context
 	tx, ok := c.Value("tx").(*pop.Connection)
 	if !ok {
-		return nil, fmt.Errorf("no transaction found")
+		return nil, errNoTransaction
 	}
 
 	// Allocate an empty Point
 	point := &models.Point{}
 
+	// Param "fields" limits the columns read from the DB
+	q, err := selectFields(tx.Q(), &models.Point{}, c.Param("fields"), "id", "created_at", "version", "company_id")
+	if err != nil {
+		return nil, models.BadRequestError(err)
+	}
+
 	// To find the Point the parameter point_id is used.
-	if err := tx.Find(point, c.Param("point_id")); err != nil {
-		return nil, c.Error(http.StatusNotFound, err)
+	if err := q.Find(point, c.Param("point_id")); err != nil {
+		return nil, models.NotFoundError("point", err)
 	}
 
-	return point, nil
+	points := models.Points{*point}
+	if err := p.include(c, tx, points); err != nil {
+		return nil, err
+	}
+
+	return &points[0], nil
+}
+
+// LoadCompanies sets the Company of the points that do not have it yet,
+// e.g. to show company names in the HTML pages.
+func (p *PointsRepository) LoadCompanies(c buffalo.Context, points []models.Point) error {
+	// Get the DB connection from the context
+	tx, ok := c.Value("tx").(*pop.Connection)
+	if !ok {
+		return errNoTransaction
+	}
+	return loadPointCompanies(tx, points)
+}
+
+// Changes gets a page of the change feed: the points changed and deleted
+// since the "since" token of the params, up to "limit" changes of the log.
+// Only the changes of transactions older than every running one are read,
+// so a transaction that commits late cannot slip in behind a token that
+// was already handed out. This function is mapped to the path
+// GET /points/changes
+func (p *PointsRepository) Changes(c buffalo.Context) (*models.PointChangeFeed, error) {
+	// Get the DB connection from the context
+	tx, ok := c.Value("tx").(*pop.Connection)
+	if !ok {
+		return nil, errNoTransaction
+	}
+
+	since, limit, err := changesWindow(c.Params())
+	if err != nil {
+		return nil, models.BadRequestError(err)
+	}
+
+	changes := models.PointChanges{}
+	err = tx.RawQuery(`SELECT * FROM point_changes
+		WHERE (txid, id) > (?, ?) AND txid < txid_snapshot_xmin(txid_current_snapshot())
+		ORDER BY txid, id LIMIT ?`, since.TxID, since.ID, limit+1).All(&changes)
+	if err != nil {
+		return nil, err
+	}
+
+	feed := &models.PointChangeFeed{Upserts: models.Points{}, Next: since.Encode()}
+	if len(changes) > limit {
+		feed.HasMore = true
+		changes = changes[:limit]
+	}
+	if len(changes) == 0 {
+		feed.Deletions = []models.PointDeletion{}
+		return feed, nil
+	}
+	last := changes[len(changes)-1]
+	feed.Next = ChangeToken{TxID: last.TxID, ID: last.ID}.Encode()
+
+	ids, deletions := compactChanges(changes)
+	feed.Deletions = deletions
+	if len(ids) == 0 {
+		return feed, nil
+	}
+
+	points := models.Points{}
+	if err := tx.Where("id IN (?)", ids).All(&points); err != nil {
+		return nil, err
+	}
+	byID := map[uuid.UUID]models.Point{}
+	for _, point := range points {
+		byID[point.ID] = point
+	}
+	// a point deleted after this page still has its tombstone ahead
+	for _, id := range ids {
+		if point, ok := byID[id]; ok {
+			feed.Upserts = append(feed.Upserts, point)
+		}
+	}
+	return feed, nil
+}
+
+// exportBatchSize is the number of Points Export reads at a time.
+const exportBatchSize = 500
+
+// Export reads all the Points that match the filters of List, newest
+// first, and passes them to fn in batches with their companies loaded.
+// The batches are read by keyset instead of pages, so the export neither
+// holds every row in memory nor slows down towards the end.
+func (p *PointsRepository) Export(c buffalo.Context, fn func(models.Points) error) error {
+	// Get the DB connection from the context
+	tx, ok := c.Value("tx").(*pop.Connection)
+	if !ok {
+		return errNoTransaction
+	}
+
+	var after *Cursor
+	for {
+		q, err := filterPoints(tx.Q(), c.Params())
+		if err != nil {
+			return models.BadRequestError(err)
+		}
+		if after != nil {
+			q = q.Where("(created_at, id) < (?, ?)", after.CreatedAt, after.ID)
+		}
+
+		points := models.Points{}
+		if err := q.Order("created_at DESC, id DESC").Limit(exportBatchSize).All(&points); err != nil {
+			return err
+		}
+		if len(points) == 0 {
+			return nil
+		}
+		if err := loadPointCompanies(tx, points); err != nil {
+			return err
+		}
+		if err := fn(points); err != nil {
+			return err
+		}
+		if len(points) < exportBatchSize {
+			return nil
+		}
+
+		last := points[len(points)-1]
+		after = &Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+}
+
+// include loads the associations named by the "include" parameter.
+func (p *PointsRepository) include(c buffalo.Context, tx *pop.Connection, points []models.Point) error {
+	includes, err := parseIncludes(c.Param("include"), "company")
+	if err != nil {
+		return models.BadRequestError(err)
+	}
+	if includes["company"] {
+		return loadPointCompanies(tx, points)
+	}
+	return nil
 }
 
 // New renders the form for creating a new Point.
 // This function is mapped to the path GET /points/new
 func (p *PointsRepository) New(c buffalo.Context) *models.Point {
-	return &models.Point{}
+	point := &models.Point{}
+
+	// Param "company_id" preselects the company, e.g. from the company page
+	if id, err := uuid.FromString(c.Param("company_id")); err == nil {
+		point.CompanyID = id
+	}
+
+	return point
 }
 
 // Create adds a Point to the DB. This function is mapped to the
 // path POST /points
 func (p *PointsRepository) Create(c buffalo.Context) (*validate.Errors, *models.Point, error) {
+	return p.create(c, uuid.Nil)
+}
+
+// CreateForCompany adds a Point of the given Company to the DB, whatever
+// company the body names. This function is mapped to the path
+// POST /companies/{company_id}/points
+func (p *PointsRepository) CreateForCompany(c buffalo.Context, companyID uuid.UUID) (*validate.Errors, *models.Point, error) {
+	return p.create(c, companyID)
+}
+
+// create binds a Point from the request and saves it. A non-nil companyID
+// replaces the bound CompanyID.
+func (p *PointsRepository) create(c buffalo.Context, companyID uuid.UUID) (*validate.Errors, *models.Point, error) {
 	// Allocate an empty Point
 	point := &models.Point{}
 
@@ -85,11 +447,14 @@ func (p *PointsRepository) Create(c buffalo.Context) (*validate.Errors, *models.
 	if err := c.Bind(point); err != nil {
 		return nil, nil, err
 	}
+	if companyID != uuid.Nil {
+		point.CompanyID = companyID
+	}
 
 	// Get the DB connection from the context
 	tx, ok := c.Value("tx").(*pop.Connection)
 	if !ok {
-		return nil, nil, fmt.Errorf("no transaction found")
+		return nil, nil, errNoTransaction
 	}
 
 	// Validate the data from the html form
@@ -101,38 +466,65 @@ func (p *PointsRepository) Create(c buffalo.Context) (*validate.Errors, *models.
 	return created, point, nil
 }
 
+// Insert validates and adds an already filled Point to the DB, e.g. one
+// decoded from an API request.
+func (p *PointsRepository) Insert(c buffalo.Context, point *models.Point) (*validate.Errors, error) {
+	// Get the DB connection from the context
+	tx, ok := c.Value("tx").(*pop.Connection)
+	if !ok {
+		return nil, errNoTransaction
+	}
+
+	return tx.ValidateAndCreate(point)
+}
+
+// Save validates and updates an already filled Point in the DB.
+func (p *PointsRepository) Save(c buffalo.Context, point *models.Point) (*validate.Errors, error) {
+	// Get the DB connection from the context
+	tx, ok := c.Value("tx").(*pop.Connection)
+	if !ok {
+		return nil, errNoTransaction
+	}
+
+	return tx.ValidateAndUpdate(point)
+}
+
 // Edit renders a edit form for a Point. This function is
 // mapped to the path GET /points/{point_id}/edit
 func (p *PointsRepository) Edit(c buffalo.Context) (*models.Point, error) {
 	// Get the DB connection from the context
 	tx, ok := c.Value("tx").(*pop.Connection)
 	if !ok {
-		return nil, fmt.Errorf("no transaction found")
+		return nil, errNoTransaction
 	}
 
 	// Allocate an empty Point
 	point := &models.Point{}
 
 	if err := tx.Find(point, c.Param("point_id")); err != nil {
-		return nil, c.Error(http.StatusNotFound, err)
+		return nil, models.NotFoundError("point", err)
 	}
 	return point, nil
 }
 
 // Update changes a Point in the DB. This function is mapped to
 // the path PUT /points/{point_id}
+// A stale version is a conflict that returns the current Point.
 func (p *PointsRepository) Update(c buffalo.Context) (*validate.Errors, *models.Point, error) {
 	// Get the DB connection from the context
 	tx, ok := c.Value("tx").(*pop.Connection)
 	if !ok {
-		return nil, nil, fmt.Errorf("no transaction found")
+		return nil, nil, errNoTransaction
 	}
 
 	// Allocate an empty Point
 	point := &models.Point{}
 
-	if err := tx.Find(point, c.Param("point_id")); err != nil {
-		return nil, nil, c.Error(http.StatusNotFound, err)
+	if err := findForChange(tx, point, c.Param("point_id")); err != nil {
+		return nil, nil, models.NotFoundError("point", err)
+	}
+	if err := checkVersion(c, "point", point.Version); err != nil {
+		return nil, point, err
 	}
 
 	// Bind Point to the html form elements
@@ -148,21 +540,56 @@ func (p *PointsRepository) Update(c buffalo.Context) (*validate.Errors, *models.
 	return updated, point, nil
 }
 
+// Patch changes only the fields of a Point that the JSON Merge Patch or
+// JSON Patch body sends. This function is mapped to the path
+// PATCH /points/{point_id}
+func (p *PointsRepository) Patch(c buffalo.Context) (*validate.Errors, *models.Point, error) {
+	// Get the DB connection from the context
+	tx, ok := c.Value("tx").(*pop.Connection)
+	if !ok {
+		return nil, nil, errNoTransaction
+	}
+
+	// Allocate an empty Point
+	point := &models.Point{}
+
+	if err := findForChange(tx, point, c.Param("point_id")); err != nil {
+		return nil, nil, models.NotFoundError("point", err)
+	}
+	if err := checkVersion(c, "point", point.Version); err != nil {
+		return nil, nil, err
+	}
+
+	if err := patchModel(c, point); err != nil {
+		return nil, nil, err
+	}
+
+	updated, err := tx.ValidateAndUpdate(point)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return updated, point, nil
+}
+
 // Destroy deletes a Point from the DB. This function is mapped
 // to the path DELETE /points/{point_id}
 func (p *PointsRepository) Destroy(c buffalo.Context) (*models.Point, error) {
 	// Get the DB connection from the context
 	tx, ok := c.Value("tx").(*pop.Connection)
 	if !ok {
-		return nil, fmt.Errorf("no transaction found")
+		return nil, errNoTransaction
 	}
 
 	// Allocate an empty Point
 	point := &models.Point{}
 
 	// To find the Point the parameter point_id is used.
-	if err := tx.Find(point, c.Param("point_id")); err != nil {
-		return nil, c.Error(http.StatusNotFound, err)
+	if err := findForChange(tx, point, c.Param("point_id")); err != nil {
+		return nil, models.NotFoundError("point", err)
+	}
+	if err := checkVersion(c, "point", point.Version); err != nil {
+		return nil, err
 	}
 
 	if err := tx.Destroy(point); err != nil {
@@ -172,29 +599,134 @@ func (p *PointsRepository) Destroy(c buffalo.Context) (*models.Point, error) {
 	return point, nil
 }
 
-// PickPointsList is a
-func (p *PointsRepository) PickPointsList(c buffalo.Context) ([]*models.Point, error) {
+// Bulk applies the operations of a PointsBulk in order and reports the
+// result of each one. decode copies the point of a create or update to the
+// Point, which an update has loaded. Every operation runs in a savepoint,
+// so a failed one is undone without aborting the transaction and the
+// others go on. It is up to the caller to roll the transaction back in the
+// atomic mode. This function is mapped to the path POST /api/v1/points/bulk
+func (p *PointsRepository) Bulk(c buffalo.Context, bulk *models.PointsBulk, decode func(json.RawMessage, *models.Point) error) (models.PointResults, error) {
+	// Get the DB connection from the context
+	tx, ok := c.Value("tx").(*pop.Connection)
+	if !ok {
+		return nil, errNoTransaction
+	}
 
-	resp, err := http.Get("http://e-solution.pickpoint.ru/api/postamatlist")
+	results := make(models.PointResults, len(bulk.Operations))
+	for i, op := range bulk.Operations {
+		if err := tx.RawQuery("SAVEPOINT bulk_operation").Exec(); err != nil {
+			return nil, err
+		}
+
+		point, err := p.applyOperation(tx, op, decode)
+		results[i] = models.PointResult{Index: i, Op: op.Op, Point: point, Err: err}
+
+		end := "RELEASE SAVEPOINT bulk_operation"
+		if err != nil {
+			end = "ROLLBACK TO SAVEPOINT bulk_operation"
+		}
+		if err := tx.RawQuery(end).Exec(); err != nil {
+			return nil, err
+		}
+	}
+
+	return results, nil
+}
+
+// applyOperation runs one operation of a PointsBulk.
+func (p *PointsRepository) applyOperation(tx *pop.Connection, op models.PointOperation, decode func(json.RawMessage, *models.Point) error) (*models.Point, error) {
+	point := &models.Point{}
+
+	if op.Op != models.BulkCreate {
+		if err := tx.Find(point, op.ID); err != nil {
+			return nil, models.NotFoundError("point", err)
+		}
+	}
+
+	if op.Op == models.BulkDelete {
+		if err := tx.Destroy(point); err != nil {
+			return nil, err
+		}
+		return point, nil
+	}
+
+	// An update keeps the fields the operation leaves out.
+	if err := decode(op.Point, point); err != nil {
+		return nil, err
+	}
+
+	var verrs *validate.Errors
+	var err error
+	if op.Op == models.BulkCreate {
+		point.ID = uuid.Nil
+		verrs, err = tx.ValidateAndCreate(point)
+	} else {
+		point.ID = op.ID
+		verrs, err = tx.ValidateAndUpdate(point)
+	}
 	if err != nil {
 		return nil, err
 	}
+	if verrs.HasAny() {
+		return point, models.ValidationError(verrs)
+	}
+	return point, nil
+}
+
+// importProgressEvery is the number of points between two reports of the
+// progress of an import.
+const importProgressEvery = 100
+
+// PickPointsList is a. progress is called with the run when the import
+// starts, every importProgressEvery points and when it is finished.
+func (p *PointsRepository) PickPointsList(c buffalo.Context, progress func(models.ImportRun)) ([]*models.Point, error) {
+	// The run is saved outside of the request transaction so that failed
+	// imports are recorded too.
+	run := &models.ImportRun{Source: "pickpoint", Status: models.ImportRunning, StartedAt: time.Now()}
+	if err := models.DB.Create(run); err != nil {
+		return nil, err
+	}
+	progress(*run)
+
+	points, err := p.importPickPoints(c, run, progress)
+
+	run.Finish(err)
+	if err := models.DB.Update(run); err != nil {
+		c.Logger().Error(err)
+	}
+	progress(*run)
+
+	return points, err
+}
+
+// importPickPoints loads the postamat list and saves it as Points of the
+// "pickpoint" company, counting the saved and rejected points in run.
+func (p *PointsRepository) importPickPoints(c buffalo.Context, run *models.ImportRun, progress func(models.ImportRun)) ([]*models.Point, error) {
+
+	resp, err := http.Get("http://e-solution.pickpoint.ru/api/postamatlist")
+	if err != nil {
+		return nil, models.UpstreamError("pickpoint", err)
+	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, models.UpstreamError("pickpoint", fmt.Errorf("unexpected status %s", resp.Status))
+	}
+
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, models.UpstreamError("pickpoint", err)
 	}
 	var points models.PointsDTO
 	err = json.Unmarshal(body, &points)
 	if err != nil {
-		return nil, err
+		return nil, models.UpstreamError("pickpoint", err)
 	}
 
 	pickPoint := models.Company{}
 	err = models.DB.Where("name = ?", "pickpoint").Last(&pickPoint)
 	if err != nil {
-		return nil, err
+		return nil, models.NotFoundError("company pickpoint", err)
 	}
 
 	pointsDB := []*models.Point{}
@@ -215,17 +747,48 @@ func (p *PointsRepository) PickPointsList(c buffalo.Context) ([]*models.Point, e
 	// Get the DB connection from the context
 	tx, ok := c.Value("tx").(*pop.Connection)
 	if !ok {
-		return nil, fmt.Errorf("no transaction found")
+		return nil, errNoTransaction
 	}
 
-	var created *validate.Errors
-	for _, pointDB := range pointsDB {
-		created, err = tx.ValidateAndCreate(pointDB)
-		if err != nil {
-			c.Logger().Error(created)
+	run.Total = len(pointsDB)
+	for i, pointDB := range pointsDB {
+		if i > 0 && i%importProgressEvery == 0 {
+			progress(*run)
+		}
+
+		verrs, err := tx.ValidateAndCreate(pointDB)
+		switch {
+		case err != nil:
+			run.Failed++
+			c.Logger().Error(err)
+		case verrs.HasAny():
+			run.Failed++
+			c.Logger().Error(verrs)
+		default:
+			run.Created++
 		}
 	}
 
 	return pointsDB, nil
 
 }
+
+// unlimited replaces a zero (unknown) limit so that it never rejects a parcel.
+const unlimited = "COALESCE(NULLIF(%s, 0), 2147483647)::bigint"
+
+// parcelFits limits q to the points where the parcel fits in some rotation.
+// The cell dimensions are sorted in SQL and compared with the sorted parcel.
+func parcelFits(q *pop.Query, parcel *models.Parcel) *pop.Query {
+	l := fmt.Sprintf(unlimited, "max_length")
+	w := fmt.Sprintf(unlimited, "max_width")
+	h := fmt.Sprintf(unlimited, "max_height")
+	largest := fmt.Sprintf("GREATEST(%s, %s, %s)", l, w, h)
+	smallest := fmt.Sprintf("LEAST(%s, %s, %s)", l, w, h)
+	middle := fmt.Sprintf("(%s + %s + %s - %s - %s)", l, w, h, largest, smallest)
+
+	d := parcel.Sorted()
+	return q.Where(largest+" >= ?", d[0]).
+		Where(middle+" >= ?", d[1]).
+		Where(smallest+" >= ?", d[2]).
+		Where("(max_weight = 0 OR max_weight >= ?)", parcel.Weight)
+}
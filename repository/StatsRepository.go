@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"fmt"
+	"location_service_v1/ls_v2/models"
+	"strings"
+	"time"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/pop"
+)
+
+// statsGroups are the columns the point statistics can be grouped by. Each
+// group selects the columns of models.PointStat it fills in.
+var statsGroups = map[string]struct {
+	selects []string
+	groups  []string
+}{
+	"company": {
+		selects: []string{"COALESCE(points.company_id::text, '') AS company_id", "COALESCE(companies.name, '') AS company"},
+		groups:  []string{"points.company_id", "companies.name"},
+	},
+	"city": {
+		selects: []string{"points.citi_name AS city"},
+		groups:  []string{"points.citi_name"},
+	},
+}
+
+// StatsRepository is a
+type StatsRepository struct {
+}
+
+// NewStatsRepository is a
+func NewStatsRepository() *StatsRepository {
+	return &StatsRepository{}
+}
+
+// Points counts Points grouped by the comma separated "group_by" parameter
+// (company, city) and how many of them changed since the "since" date,
+// seven days ago by default. This function is mapped to the path
+// GET /stats/points
+func (p *StatsRepository) Points(c buffalo.Context) (*models.PointStats, time.Time, error) {
+	// Get the DB connection from the context
+	tx, ok := c.Value("tx").(*pop.Connection)
+	if !ok {
+		return nil, time.Time{}, errNoTransaction
+	}
+
+	since := time.Now().AddDate(0, 0, -7).Truncate(24 * time.Hour)
+	if s := c.Param("since"); s != "" {
+		var err error
+		if since, err = parseTime(s); err != nil {
+			return nil, since, models.BadRequestError(fmt.Errorf("since: %v", err))
+		}
+	}
+
+	selects := []string{}
+	groups := []string{}
+	for _, name := range strings.Split(c.Param("group_by"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		group, ok := statsGroups[name]
+		if !ok {
+			return nil, since, models.BadRequestError(fmt.Errorf("group_by: unknown group %q", name))
+		}
+		selects = append(selects, group.selects...)
+		groups = append(groups, group.groups...)
+	}
+
+	sql := `SELECT ` + strings.Join(append(selects,
+		"COUNT(points.id) AS points",
+		"COUNT(points.id) FILTER (WHERE points.created_at >= ?) AS created",
+		"COUNT(points.id) FILTER (WHERE points.created_at < ? AND points.updated_at >= ?) AS updated",
+	), ", ") + `
+		FROM points
+		LEFT JOIN companies ON companies.id = points.company_id`
+	if len(groups) > 0 {
+		sql += `
+		GROUP BY ` + strings.Join(groups, ", ") + `
+		ORDER BY ` + strings.Join(groups, ", ")
+	}
+
+	stats := &models.PointStats{}
+	if err := tx.RawQuery(sql, since, since, since).All(stats); err != nil {
+		return nil, since, err
+	}
+
+	return stats, since, nil
+}
+
+// Number of days counted as recent and of import runs on the dashboard.
+const (
+	dashboardRecentDays = 7
+	dashboardImportRuns = 5
+)
+
+// Dashboard gets the totals, the latest import runs, the recently created
+// points and the data-quality problems shown on the home page. This function
+// is mapped to the path GET /
+func (p *StatsRepository) Dashboard(c buffalo.Context) (*models.Dashboard, error) {
+	// Get the DB connection from the context
+	tx, ok := c.Value("tx").(*pop.Connection)
+	if !ok {
+		return nil, errNoTransaction
+	}
+
+	d := &models.Dashboard{
+		RecentSince: time.Now().AddDate(0, 0, -dashboardRecentDays).Truncate(24 * time.Hour),
+		Problems:    map[string]int{},
+	}
+
+	var err error
+	if d.Points, err = tx.Count(&models.Point{}); err != nil {
+		return nil, err
+	}
+	if d.Companies, err = tx.Count(&models.Company{}); err != nil {
+		return nil, err
+	}
+	if d.Users, err = tx.Count(&models.User{}); err != nil {
+		return nil, err
+	}
+
+	// Same condition as the "created_after" filter the dashboard links to
+	if d.RecentPoints, err = tx.Where("created_at > ?", d.RecentSince).Count(&models.Point{}); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Order("started_at DESC").Limit(dashboardImportRuns).All(&d.ImportRuns); err != nil {
+		return nil, err
+	}
+
+	for name, condition := range pointProblems {
+		if d.Problems[name], err = tx.Where(condition).Count(&models.Point{}); err != nil {
+			return nil, err
+		}
+	}
+
+	return d, nil
+}
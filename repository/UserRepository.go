@@ -1,12 +1,10 @@
 package repository
 
 import (
-	"fmt"
 	"github.com/gobuffalo/buffalo"
 	"github.com/gobuffalo/pop"
 	"github.com/gobuffalo/validate"
 	"location_service_v1/ls_v2/models"
-	"net/http"
 )
 
 // UsersRepository is a
@@ -18,13 +16,26 @@ func NewUsersRepository() *UsersRepository {
 	return &UsersRepository{}
 }
 
+// userFilters are the query parameters accepted by List.
+var userFilters = map[string]filter{
+	"name":          contains("name"),
+	"created_after": after("created_at"),
+}
+
+// userSortColumns are the fields List can be sorted by.
+var userSortColumns = map[string]string{
+	"name":       "name",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
 // List gets all Users. This function is mapped to the path
 // GET /users
 func (p *UsersRepository) List(c buffalo.Context) (*models.Users, *pop.Query, error) {
 	// Get the DB connection from the context
 	tx, ok := c.Value("tx").(*pop.Connection)
 	if !ok {
-		return nil, nil, fmt.Errorf("no transaction found")
+		return nil, nil, errNoTransaction
 	}
 
 	users := &models.Users{}
@@ -33,6 +44,18 @@ func (p *UsersRepository) List(c buffalo.Context) (*models.Users, *pop.Query, er
 	// Default values are "page=1" and "per_page=20".
 	q := tx.PaginateFromParams(c.Params())
 
+	// Whitelisted filters and "sort" from the query string.
+	q, err := applyFilters(q, c.Params(), userFilters)
+	if err != nil {
+		return nil, nil, models.BadRequestError(err)
+	}
+	if q, err = applySort(q, c.Param("sort"), userSortColumns, "-created_at"); err != nil {
+		return nil, nil, models.BadRequestError(err)
+	}
+	if q, err = selectFields(q, &models.User{}, c.Param("fields"), "id", "created_at"); err != nil {
+		return nil, nil, models.BadRequestError(err)
+	}
+
 	// Retrieve all Companies from the DB
 	if err := q.All(users); err != nil {
 		return nil, nil, err
@@ -41,21 +64,59 @@ func (p *UsersRepository) List(c buffalo.Context) (*models.Users, *pop.Query, er
 	return users, q, nil
 }
 
+// Scroll gets Users page by page with the "after", "before" and "limit"
+// keyset cursors, newest first. This function is mapped to the path
+// GET /users for JSON and XML clients
+func (p *UsersRepository) Scroll(c buffalo.Context) (*models.Users, *CursorPage, error) {
+	// Get the DB connection from the context
+	tx, ok := c.Value("tx").(*pop.Connection)
+	if !ok {
+		return nil, nil, errNoTransaction
+	}
+
+	users := &models.Users{}
+
+	window, err := newCursorWindow(c.Params())
+	if err != nil {
+		return nil, nil, models.BadRequestError(err)
+	}
+
+	q, err := applyFilters(tx.Q(), c.Params(), userFilters)
+	if err != nil {
+		return nil, nil, models.BadRequestError(err)
+	}
+	if q, err = selectFields(q, &models.User{}, c.Param("fields"), "id", "created_at"); err != nil {
+		return nil, nil, models.BadRequestError(err)
+	}
+
+	if err := window.apply(q).All(users); err != nil {
+		return nil, nil, err
+	}
+
+	return users, window.page(users), nil
+}
+
 // Show gets the data for one Point. This function is mapped to
 // the path GET /users/{user_id}
 func (p *UsersRepository) Show(c buffalo.Context) (*models.User, error) {
 	// Get the DB connection from the context
 	tx, ok := c.Value("tx").(*pop.Connection)
 	if !ok {
-		return nil, fmt.Errorf("no transaction found")
+		return nil, errNoTransaction
 	}
 
 	// Allocate an empty User
 	user := &models.User{}
 
+	// Param "fields" limits the columns read from the DB
+	q, err := selectFields(tx.Q(), &models.User{}, c.Param("fields"), "id", "created_at", "version")
+	if err != nil {
+		return nil, models.BadRequestError(err)
+	}
+
 	// To find the User the parameter user_id is used.
-	if err := tx.Find(user, c.Param("user_id")); err != nil {
-		return nil, c.Error(http.StatusNotFound, err)
+	if err := q.Find(user, c.Param("user_id")); err != nil {
+		return nil, models.NotFoundError("user", err)
 	}
 
 	return user, nil
@@ -81,7 +142,7 @@ func (p *UsersRepository) Create(c buffalo.Context) (*validate.Errors, *models.U
 	// Get the DB connection from the context
 	tx, ok := c.Value("tx").(*pop.Connection)
 	if !ok {
-		return nil, nil, fmt.Errorf("no transaction found")
+		return nil, nil, errNoTransaction
 	}
 
 	// Validate the data from the html form
@@ -93,38 +154,65 @@ func (p *UsersRepository) Create(c buffalo.Context) (*validate.Errors, *models.U
 	return created, user, nil
 }
 
+// Insert validates and adds an already filled User to the DB, e.g. one
+// decoded from an API request.
+func (p *UsersRepository) Insert(c buffalo.Context, user *models.User) (*validate.Errors, error) {
+	// Get the DB connection from the context
+	tx, ok := c.Value("tx").(*pop.Connection)
+	if !ok {
+		return nil, errNoTransaction
+	}
+
+	return tx.ValidateAndCreate(user)
+}
+
+// Save validates and updates an already filled User in the DB.
+func (p *UsersRepository) Save(c buffalo.Context, user *models.User) (*validate.Errors, error) {
+	// Get the DB connection from the context
+	tx, ok := c.Value("tx").(*pop.Connection)
+	if !ok {
+		return nil, errNoTransaction
+	}
+
+	return tx.ValidateAndUpdate(user)
+}
+
 // Edit renders a edit form for a User. This function is
 // mapped to the path GET /users/{user_id}/edit
 func (p *UsersRepository) Edit(c buffalo.Context) (*models.User, error) {
 	// Get the DB connection from the context
 	tx, ok := c.Value("tx").(*pop.Connection)
 	if !ok {
-		return nil, fmt.Errorf("no transaction found")
+		return nil, errNoTransaction
 	}
 
 	// Allocate an empty User
 	user := &models.User{}
 
 	if err := tx.Find(user, c.Param("user_id")); err != nil {
-		return nil, c.Error(http.StatusNotFound, err)
+		return nil, models.NotFoundError("user", err)
 	}
 	return user, nil
 }
 
 // Update changes a User in the DB. This function is mapped to
 // the path PUT /users/{user_id}
+// A stale version is a conflict that returns the current User.
 func (p *UsersRepository) Update(c buffalo.Context) (*validate.Errors, *models.User, error) {
 	// Get the DB connection from the context
 	tx, ok := c.Value("tx").(*pop.Connection)
 	if !ok {
-		return nil, nil, fmt.Errorf("no transaction found")
+		return nil, nil, errNoTransaction
 	}
 
 	// Allocate an empty User
 	user := &models.User{}
 
-	if err := tx.Find(user, c.Param("user_id")); err != nil {
-		return nil, nil, c.Error(http.StatusNotFound, err)
+	if err := findForChange(tx, user, c.Param("user_id")); err != nil {
+		return nil, nil, models.NotFoundError("user", err)
+	}
+	if err := checkVersion(c, "user", user.Version); err != nil {
+		return nil, user, err
 	}
 
 	// Bind User to the html form elements
@@ -140,21 +228,56 @@ func (p *UsersRepository) Update(c buffalo.Context) (*validate.Errors, *models.U
 	return updated, user, nil
 }
 
+// Patch changes only the fields of a User that the JSON Merge Patch or
+// JSON Patch body sends. This function is mapped to the path
+// PATCH /users/{user_id}
+func (p *UsersRepository) Patch(c buffalo.Context) (*validate.Errors, *models.User, error) {
+	// Get the DB connection from the context
+	tx, ok := c.Value("tx").(*pop.Connection)
+	if !ok {
+		return nil, nil, errNoTransaction
+	}
+
+	// Allocate an empty User
+	user := &models.User{}
+
+	if err := findForChange(tx, user, c.Param("user_id")); err != nil {
+		return nil, nil, models.NotFoundError("user", err)
+	}
+	if err := checkVersion(c, "user", user.Version); err != nil {
+		return nil, nil, err
+	}
+
+	if err := patchModel(c, user); err != nil {
+		return nil, nil, err
+	}
+
+	updated, err := tx.ValidateAndUpdate(user)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return updated, user, nil
+}
+
 // Destroy deletes a User from the DB. This function is mapped
 // to the path DELETE /users/{user_id}
 func (p *UsersRepository) Destroy(c buffalo.Context) (*models.User, error) {
 	// Get the DB connection from the context
 	tx, ok := c.Value("tx").(*pop.Connection)
 	if !ok {
-		return nil, fmt.Errorf("no transaction found")
+		return nil, errNoTransaction
 	}
 
 	// Allocate an empty User
 	user := &models.User{}
 
 	// To find the Point the parameter user_id is used.
-	if err := tx.Find(user, c.Param("user_id")); err != nil {
-		return nil, c.Error(http.StatusNotFound, err)
+	if err := findForChange(tx, user, c.Param("user_id")); err != nil {
+		return nil, models.NotFoundError("user", err)
+	}
+	if err := checkVersion(c, "user", user.Version); err != nil {
+		return nil, err
 	}
 
 	if err := tx.Destroy(user); err != nil {
@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"location_service_v1/ls_v2/models"
+	"time"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/pop"
+	"github.com/gobuffalo/validate"
+)
+
+// WebhooksRepository is a
+type WebhooksRepository struct {
+}
+
+// NewWebhooksRepository is a
+func NewWebhooksRepository() *WebhooksRepository {
+	return &WebhooksRepository{}
+}
+
+// List gets all WebhookSubscriptions, newest first. This function is
+// mapped to the path GET /webhooks
+func (p *WebhooksRepository) List(c buffalo.Context) (*models.WebhookSubscriptions, *pop.Query, error) {
+	// Get the DB connection from the context
+	tx, ok := c.Value("tx").(*pop.Connection)
+	if !ok {
+		return nil, nil, errNoTransaction
+	}
+
+	subscriptions := &models.WebhookSubscriptions{}
+
+	// Paginate results. Params "page" and "per_page" control pagination.
+	q := tx.PaginateFromParams(c.Params()).Order("created_at DESC")
+	if err := q.All(subscriptions); err != nil {
+		return nil, nil, err
+	}
+
+	return subscriptions, q, nil
+}
+
+// Show gets the data for one WebhookSubscription. This function is mapped
+// to the path GET /webhooks/{webhook_id}
+func (p *WebhooksRepository) Show(c buffalo.Context) (*models.WebhookSubscription, error) {
+	// Get the DB connection from the context
+	tx, ok := c.Value("tx").(*pop.Connection)
+	if !ok {
+		return nil, errNoTransaction
+	}
+
+	subscription := &models.WebhookSubscription{}
+	if err := tx.Find(subscription, c.Param("webhook_id")); err != nil {
+		return nil, models.NotFoundError("webhook", err)
+	}
+	return subscription, nil
+}
+
+// Deliveries gets the delivery log of the webhook_id of the path, newest
+// first.
+func (p *WebhooksRepository) Deliveries(c buffalo.Context) (*models.WebhookDeliveries, *pop.Query, error) {
+	// Get the DB connection from the context
+	tx, ok := c.Value("tx").(*pop.Connection)
+	if !ok {
+		return nil, nil, errNoTransaction
+	}
+
+	deliveries := &models.WebhookDeliveries{}
+
+	q := tx.PaginateFromParams(c.Params()).
+		Where("subscription_id = ?", c.Param("webhook_id")).
+		Order("created_at DESC, id DESC")
+	if err := q.All(deliveries); err != nil {
+		return nil, nil, err
+	}
+
+	return deliveries, q, nil
+}
+
+// Create adds a WebhookSubscription to the DB. This function is mapped to
+// the path POST /webhooks
+func (p *WebhooksRepository) Create(c buffalo.Context) (*validate.Errors, *models.WebhookSubscription, error) {
+	subscription := &models.WebhookSubscription{}
+
+	// Bind subscription to the html form elements
+	if err := c.Bind(subscription); err != nil {
+		return nil, nil, err
+	}
+
+	// Get the DB connection from the context
+	tx, ok := c.Value("tx").(*pop.Connection)
+	if !ok {
+		return nil, nil, errNoTransaction
+	}
+
+	created, err := tx.ValidateAndCreate(subscription)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return created, subscription, nil
+}
+
+// Update changes a WebhookSubscription in the DB. This function is mapped
+// to the path PUT /webhooks/{webhook_id}
+func (p *WebhooksRepository) Update(c buffalo.Context) (*validate.Errors, *models.WebhookSubscription, error) {
+	// Get the DB connection from the context
+	tx, ok := c.Value("tx").(*pop.Connection)
+	if !ok {
+		return nil, nil, errNoTransaction
+	}
+
+	subscription := &models.WebhookSubscription{}
+	if err := tx.Find(subscription, c.Param("webhook_id")); err != nil {
+		return nil, nil, models.NotFoundError("webhook", err)
+	}
+
+	// Bind subscription to the html form elements
+	if err := c.Bind(subscription); err != nil {
+		return nil, nil, err
+	}
+
+	updated, err := tx.ValidateAndUpdate(subscription)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return updated, subscription, nil
+}
+
+// Destroy deletes a WebhookSubscription and its delivery log from the DB.
+// This function is mapped to the path DELETE /webhooks/{webhook_id}
+func (p *WebhooksRepository) Destroy(c buffalo.Context) (*models.WebhookSubscription, error) {
+	// Get the DB connection from the context
+	tx, ok := c.Value("tx").(*pop.Connection)
+	if !ok {
+		return nil, errNoTransaction
+	}
+
+	subscription := &models.WebhookSubscription{}
+	if err := tx.Find(subscription, c.Param("webhook_id")); err != nil {
+		return nil, models.NotFoundError("webhook", err)
+	}
+
+	if err := tx.Destroy(subscription); err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+// Resend queues the delivery_id of the path again as a new delivery.
+// This function is mapped to the path
+// POST /webhooks/{webhook_id}/deliveries/{delivery_id}/resend
+func (p *WebhooksRepository) Resend(c buffalo.Context) (*models.WebhookDelivery, error) {
+	// Get the DB connection from the context
+	tx, ok := c.Value("tx").(*pop.Connection)
+	if !ok {
+		return nil, errNoTransaction
+	}
+
+	delivery := &models.WebhookDelivery{}
+	err := tx.Where("subscription_id = ?", c.Param("webhook_id")).Find(delivery, c.Param("delivery_id"))
+	if err != nil {
+		return nil, models.NotFoundError("delivery", err)
+	}
+
+	again, err := delivery.Resend(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Create(again); err != nil {
+		return nil, err
+	}
+	return again, nil
+}
+
+// ActiveSubscriptions gets the subscriptions that Enqueue sends to. A
+// request that changes many records loads them once.
+func (p *WebhooksRepository) ActiveSubscriptions(c buffalo.Context) (models.WebhookSubscriptions, error) {
+	// Get the DB connection from the context
+	tx, ok := c.Value("tx").(*pop.Connection)
+	if !ok {
+		return nil, errNoTransaction
+	}
+
+	subscriptions := models.WebhookSubscriptions{}
+	if err := tx.Where("active = ?", true).All(&subscriptions); err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// Enqueue adds a pending delivery of the event for each of the active
+// subscriptions that asks for it. The deliveries are saved in the
+// transaction of the change, so they are sent only if it commits.
+func (p *WebhooksRepository) Enqueue(c buffalo.Context, subscriptions models.WebhookSubscriptions, event string, data interface{}) error {
+	// Get the DB connection from the context
+	tx, ok := c.Value("tx").(*pop.Connection)
+	if !ok {
+		return errNoTransaction
+	}
+
+	now := time.Now()
+	for _, s := range subscriptions {
+		if !s.Subscribes(event) {
+			continue
+		}
+		delivery, err := models.NewWebhookDelivery(s.ID, event, data, now)
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(delivery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"location_service_v1/ls_v2/models"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gofrs/uuid"
+)
+
+// Default and maximum number of changes in a page of the change feed.
+const (
+	defaultChangesLimit = 100
+	maxChangesLimit     = 1000
+)
+
+// ChangeToken is a position in the change log of points, which is read in
+// txid and id order. The zero token is the start of the log.
+type ChangeToken struct {
+	TxID int64
+	ID   int64
+}
+
+// Encode returns the opaque form of the token used in the "since" parameter.
+func (t ChangeToken) Encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d.%d", t.TxID, t.ID)))
+}
+
+// DecodeChangeToken reads a token made by Encode.
+func DecodeChangeToken(s string) (ChangeToken, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return ChangeToken{}, fmt.Errorf("invalid change token")
+	}
+	parts := strings.SplitN(string(b), ".", 2)
+	if len(parts) != 2 {
+		return ChangeToken{}, fmt.Errorf("invalid change token")
+	}
+	txid, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || txid < 0 {
+		return ChangeToken{}, fmt.Errorf("invalid change token")
+	}
+	id, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || id < 0 {
+		return ChangeToken{}, fmt.Errorf("invalid change token")
+	}
+	return ChangeToken{TxID: txid, ID: id}, nil
+}
+
+// changesWindow reads "since" and "limit" from params. A missing "since"
+// starts from the beginning of the log.
+func changesWindow(params buffalo.ParamValues) (ChangeToken, int, error) {
+	limit := defaultChangesLimit
+	if l := params.Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 {
+			return ChangeToken{}, 0, fmt.Errorf("limit must be a positive integer")
+		}
+		if n > maxChangesLimit {
+			n = maxChangesLimit
+		}
+		limit = n
+	}
+
+	since := ChangeToken{}
+	if s := params.Get("since"); s != "" {
+		var err error
+		if since, err = DecodeChangeToken(s); err != nil {
+			return ChangeToken{}, 0, err
+		}
+	}
+	return since, limit, nil
+}
+
+// compactChanges keeps the last change of each point, in log order. The
+// points still there are returned by id to be loaded, the others as
+// tombstones.
+func compactChanges(changes models.PointChanges) ([]uuid.UUID, []models.PointDeletion) {
+	last := map[uuid.UUID]int{}
+	for i, change := range changes {
+		last[change.PointID] = i
+	}
+
+	upserts := []uuid.UUID{}
+	deletions := []models.PointDeletion{}
+	for i, change := range changes {
+		if last[change.PointID] != i {
+			continue
+		}
+		if change.Deleted {
+			deletions = append(deletions, models.PointDeletion{ID: change.PointID, DeletedAt: change.ChangedAt})
+			continue
+		}
+		upserts = append(upserts, change.PointID)
+	}
+	return upserts, deletions
+}
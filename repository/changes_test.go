@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"location_service_v1/ls_v2/models"
+
+	"github.com/gofrs/uuid"
+)
+
+func Test_ChangeToken_RoundTrip(t *testing.T) {
+	token := ChangeToken{TxID: 9000000001, ID: 42}
+
+	decoded, err := DecodeChangeToken(token.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded != token {
+		t.Fatalf("expected %+v, got %+v", token, decoded)
+	}
+
+	for _, s := range []string{"not-a-token", ChangeToken{TxID: -1}.Encode()} {
+		if _, err := DecodeChangeToken(s); err == nil {
+			t.Errorf("expected an error for %q", s)
+		}
+	}
+}
+
+func Test_changesWindow(t *testing.T) {
+	since, limit, err := changesWindow(url.Values{})
+	if err != nil || since != (ChangeToken{}) || limit != defaultChangesLimit {
+		t.Errorf("got %+v, %d, %v for no params", since, limit, err)
+	}
+
+	_, limit, err = changesWindow(url.Values{"limit": {"5000"}})
+	if err != nil || limit != maxChangesLimit {
+		t.Errorf("got %d, %v for a large limit", limit, err)
+	}
+
+	if _, _, err := changesWindow(url.Values{"limit": {"0"}}); err == nil {
+		t.Error("expected an error for a zero limit")
+	}
+}
+
+func Test_compactChanges(t *testing.T) {
+	a, b, c := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	now := time.Now()
+
+	ids, deletions := compactChanges(models.PointChanges{
+		{ID: 1, PointID: a},
+		{ID: 2, PointID: b},
+		{ID: 3, PointID: a},
+		{ID: 4, PointID: b, Deleted: true, ChangedAt: now},
+		{ID: 5, PointID: c, Deleted: true, ChangedAt: now},
+		{ID: 6, PointID: c},
+	})
+
+	if len(ids) != 2 || ids[0] != a || ids[1] != c {
+		t.Errorf("got upserts %v, want [%s %s]", ids, a, c)
+	}
+	if len(deletions) != 1 || deletions[0].ID != b || !deletions[0].DeletedAt.Equal(now) {
+		t.Errorf("got deletions %+v, want %s", deletions, b)
+	}
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"fmt"
+	"location_service_v1/ls_v2/models"
+	"strconv"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/pop"
+	"github.com/gobuffalo/pop/columns"
+)
+
+// findForChange finds the record with the given id before an update or a
+// delete. The row stays locked until the end of the transaction, so that
+// its version cannot change between checkVersion and the save.
+func findForChange(tx *pop.Connection, model interface{}, id string) error {
+	table := (&pop.Model{Value: model}).TableName()
+	sql := fmt.Sprintf("SELECT %s FROM %s WHERE id = ? FOR UPDATE",
+		columns.ForStruct(model, table).Readable().SelectString(), table)
+	return tx.RawQuery(sql, id).First(model)
+}
+
+// checkVersion compares the version of a record about to change with the
+// one the client has seen. API clients send its ETag in the If-Match header
+// and get a PreconditionFailedError when it is stale. The HTML edit form
+// sends it in the "version" field and gets a ConflictError, which shows the
+// conflict page. Requests with neither change the current version.
+func checkVersion(c buffalo.Context, what string, version int) error {
+	if match := c.Request().Header.Get("If-Match"); match != "" {
+		if !models.MatchETag(match, models.ETag(version), false) {
+			return models.PreconditionFailedError(what + " has changed since it was read")
+		}
+		return nil
+	}
+
+	if seen := c.Param("version"); seen != "" && seen != strconv.Itoa(version) {
+		return models.ConflictError(what + " was changed by someone else")
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/pop"
+	"github.com/gofrs/uuid"
+)
+
+// Default and maximum page sizes of keyset pagination.
+const (
+	defaultCursorLimit = 20
+	maxCursorLimit     = 100
+)
+
+// Cursor is a position in a list ordered by created_at and id, newest first.
+type Cursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uuid.UUID `json:"id"`
+}
+
+// Encode returns the opaque form of the cursor used in query parameters.
+func (cur Cursor) Encode() string {
+	b, _ := json.Marshal(cur)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor reads a cursor made by Encode.
+func DecodeCursor(s string) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	cur := &Cursor{}
+	if err := json.Unmarshal(b, cur); err != nil || cur.ID == uuid.Nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	return cur, nil
+}
+
+// CursorPage is the result of keyset pagination. Empty cursors mean there is
+// nothing more in that direction.
+type CursorPage struct {
+	Limit      int
+	NextCursor string
+	PrevCursor string
+}
+
+// CursorRequested reports whether the params ask for keyset pagination
+// with "after", "before" or "limit" instead of "page" and "per_page".
+func CursorRequested(params buffalo.ParamValues) bool {
+	for _, name := range []string{"after", "before", "limit"} {
+		if params.Get(name) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// cursorWindow is one keyset page requested by the params.
+type cursorWindow struct {
+	limit  int
+	after  *Cursor
+	before *Cursor
+}
+
+// newCursorWindow reads "after", "before" and "limit" from params.
+func newCursorWindow(params buffalo.ParamValues) (*cursorWindow, error) {
+	w := &cursorWindow{limit: defaultCursorLimit}
+
+	if l := params.Get("limit"); l != "" {
+		limit, err := strconv.Atoi(l)
+		if err != nil || limit <= 0 {
+			return nil, fmt.Errorf("limit must be a positive integer")
+		}
+		if limit > maxCursorLimit {
+			limit = maxCursorLimit
+		}
+		w.limit = limit
+	}
+
+	if params.Get("sort") != "" {
+		return nil, fmt.Errorf("sort is not supported with cursor pagination")
+	}
+	if params.Get("after") != "" && params.Get("before") != "" {
+		return nil, fmt.Errorf("after and before cannot be used together")
+	}
+
+	var err error
+	if a := params.Get("after"); a != "" {
+		if w.after, err = DecodeCursor(a); err != nil {
+			return nil, err
+		}
+	}
+	if b := params.Get("before"); b != "" {
+		if w.before, err = DecodeCursor(b); err != nil {
+			return nil, err
+		}
+	}
+
+	return w, nil
+}
+
+// apply limits q to the window. One extra row is fetched to find out whether
+// there is another page. Pages before a cursor are read in ascending order
+// and put back in order by page.
+func (w *cursorWindow) apply(q *pop.Query) *pop.Query {
+	switch {
+	case w.before != nil:
+		q = q.Where("(created_at, id) > (?, ?)", w.before.CreatedAt, w.before.ID).
+			Order("created_at ASC, id ASC")
+	case w.after != nil:
+		q = q.Where("(created_at, id) < (?, ?)", w.after.CreatedAt, w.after.ID).
+			Order("created_at DESC, id DESC")
+	default:
+		q = q.Order("created_at DESC, id DESC")
+	}
+	return q.Limit(w.limit + 1)
+}
+
+// page trims the extra row from rows, a pointer to a slice of models with
+// ID and CreatedAt fields, restores the newest first order and works out
+// the cursors of the neighbouring pages.
+func (w *cursorWindow) page(rows interface{}) *CursorPage {
+	slice := reflect.ValueOf(rows).Elem()
+	more := slice.Len() > w.limit
+	if more {
+		slice.Set(slice.Slice(0, w.limit))
+	}
+
+	if w.before != nil {
+		swap := reflect.Swapper(slice.Interface())
+		for i, j := 0, slice.Len()-1; i < j; i, j = i+1, j-1 {
+			swap(i, j)
+		}
+	}
+
+	page := &CursorPage{Limit: w.limit}
+	if slice.Len() == 0 {
+		return page
+	}
+
+	cursorAt := func(i int) string {
+		row := reflect.Indirect(slice.Index(i))
+		return Cursor{
+			CreatedAt: row.FieldByName("CreatedAt").Interface().(time.Time),
+			ID:        row.FieldByName("ID").Interface().(uuid.UUID),
+		}.Encode()
+	}
+
+	if w.before != nil {
+		page.NextCursor = cursorAt(slice.Len() - 1)
+		if more {
+			page.PrevCursor = cursorAt(0)
+		}
+	} else {
+		if more {
+			page.NextCursor = cursorAt(slice.Len() - 1)
+		}
+		if w.after != nil {
+			page.PrevCursor = cursorAt(0)
+		}
+	}
+
+	return page
+}
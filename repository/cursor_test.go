@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"location_service_v1/ls_v2/models"
+
+	"github.com/gofrs/uuid"
+)
+
+func Test_Cursor_RoundTrip(t *testing.T) {
+	cur := Cursor{CreatedAt: time.Date(2020, 3, 1, 10, 0, 0, 123000, time.UTC), ID: uuid.Must(uuid.NewV4())}
+
+	decoded, err := DecodeCursor(cur.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.CreatedAt.Equal(cur.CreatedAt) || decoded.ID != cur.ID {
+		t.Fatalf("expected %+v, got %+v", cur, decoded)
+	}
+
+	if _, err := DecodeCursor("not-a-cursor"); err == nil {
+		t.Fatal("expected an error for a broken cursor")
+	}
+}
+
+func Test_cursorWindow_page(t *testing.T) {
+	now := time.Now()
+	newPoints := func(n int) *models.Points {
+		points := models.Points{}
+		for i := 0; i < n; i++ {
+			points = append(points, models.Point{ID: uuid.Must(uuid.NewV4()), CreatedAt: now.Add(-time.Duration(i) * time.Minute)})
+		}
+		return &points
+	}
+
+	w, err := newCursorWindow(url.Values{"limit": {"2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	points := newPoints(3)
+	page := w.page(points)
+	if len(*points) != 2 || page.NextCursor == "" || page.PrevCursor != "" {
+		t.Fatalf("unexpected first page %+v of %d points", page, len(*points))
+	}
+
+	// rows before a cursor come in ascending order and are reversed
+	w, err = newCursorWindow(url.Values{"limit": {"2"}, "before": {page.NextCursor}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	points = newPoints(2)
+	first := (*points)[0].ID
+	page = w.page(points)
+	if (*points)[1].ID != first || page.NextCursor == "" || page.PrevCursor != "" {
+		t.Fatalf("unexpected previous page %+v", page)
+	}
+
+	if _, err := newCursorWindow(url.Values{"limit": {"2"}, "sort": {"name"}}); err == nil {
+		t.Fatal("expected an error for sort with cursors")
+	}
+}
@@ -0,0 +1,7 @@
+package repository
+
+import "errors"
+
+// errNoTransaction means the transaction middleware did not run for the
+// request. It is a programming error, answered as an internal error.
+var errNoTransaction = errors.New("no transaction found")
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gobuffalo/pop"
+)
+
+// selectFields limits the columns read by q to the comma separated json keys
+// of the "fields" parameter. The always columns are read in any case, e.g.
+// the id and the keys needed for cursors and includes. Keys of associations
+// are accepted but have no column. An empty param selects everything.
+func selectFields(q *pop.Query, model interface{}, param string, always ...string) (*pop.Query, error) {
+	if strings.TrimSpace(param) == "" {
+		return q, nil
+	}
+
+	columns := map[string]string{}
+	t := reflect.Indirect(reflect.ValueOf(model)).Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key := strings.Split(f.Tag.Get("json"), ",")[0]
+		if key == "" || key == "-" {
+			continue
+		}
+		columns[key] = strings.Split(f.Tag.Get("db"), ",")[0]
+	}
+
+	selected := map[string]bool{}
+	for _, column := range always {
+		selected[column] = true
+	}
+	for _, key := range strings.Split(param, ",") {
+		key = strings.TrimSpace(key)
+		column, ok := columns[key]
+		if !ok {
+			return nil, fmt.Errorf("fields: unknown field %q", key)
+		}
+		if column != "" && column != "-" {
+			selected[column] = true
+		}
+	}
+
+	names := make([]string, 0, len(selected))
+	for column := range selected {
+		names = append(names, column)
+	}
+	return q.Select(names...), nil
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"testing"
+
+	"location_service_v1/ls_v2/models"
+
+	"github.com/gobuffalo/pop"
+)
+
+func Test_selectFields(t *testing.T) {
+	q := pop.Q(&pop.Connection{})
+	if _, err := selectFields(q, &models.Point{}, "id,name,citiName,company", "id"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := selectFields(q, &models.Point{}, "name,search_vector", "id"); err == nil {
+		t.Fatal("expected an error for a field the model does not serialize")
+	}
+}
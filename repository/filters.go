@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/pop"
+	"github.com/gofrs/uuid"
+)
+
+// filter narrows a list query by the value of one query parameter.
+type filter func(q *pop.Query, value string) (*pop.Query, error)
+
+// applyFilters applies the filters whose parameters are present in params.
+// Parameters missing from the whitelist are ignored.
+func applyFilters(q *pop.Query, params buffalo.ParamValues, filters map[string]filter) (*pop.Query, error) {
+	for name, f := range filters {
+		value := strings.TrimSpace(params.Get(name))
+		if value == "" {
+			continue
+		}
+		var err error
+		if q, err = f(q, value); err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+	}
+	return q, nil
+}
+
+// applySort orders q by the comma separated "sort" parameter, e.g.
+// "name,-created_at". A leading "-" sorts descending. Only keys of columns
+// are accepted. The id is always added last so pages stay stable.
+func applySort(q *pop.Query, sort string, columns map[string]string, fallback string) (*pop.Query, error) {
+	if strings.TrimSpace(sort) == "" {
+		sort = fallback
+	}
+
+	order := []string{}
+	for _, key := range strings.Split(sort, ",") {
+		key = strings.TrimSpace(key)
+		direction := "ASC"
+		if strings.HasPrefix(key, "-") {
+			key = key[1:]
+			direction = "DESC"
+		}
+		column, ok := columns[key]
+		if !ok {
+			return nil, fmt.Errorf("sort: unknown field %q", key)
+		}
+		order = append(order, column+" "+direction)
+	}
+	order = append(order, "id ASC")
+
+	return q.Order(strings.Join(order, ", ")), nil
+}
+
+// equalsFold matches the column ignoring the case.
+func equalsFold(column string) filter {
+	return func(q *pop.Query, value string) (*pop.Query, error) {
+		return q.Where("LOWER("+column+") = LOWER(?)", value), nil
+	}
+}
+
+// equalsInt matches an integer column.
+func equalsInt(column string) filter {
+	return func(q *pop.Query, value string) (*pop.Query, error) {
+		i, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an integer", value)
+		}
+		return q.Where(column+" = ?", i), nil
+	}
+}
+
+// equalsUUID matches a uuid column.
+func equalsUUID(column string) filter {
+	return func(q *pop.Query, value string) (*pop.Query, error) {
+		id, err := uuid.FromString(value)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a valid id", value)
+		}
+		return q.Where(column+" = ?", id), nil
+	}
+}
+
+// contains matches the column containing the value, ignoring the case.
+func contains(column string) filter {
+	return func(q *pop.Query, value string) (*pop.Query, error) {
+		return q.Where(column+" ILIKE ?", "%"+escapeLike(value)+"%"), nil
+	}
+}
+
+// escapeLike escapes the LIKE wildcards in value.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+// after matches rows where the time column is later than the value. Both
+// RFC 3339 timestamps and plain dates are accepted.
+func after(column string) filter {
+	return func(q *pop.Query, value string) (*pop.Query, error) {
+		t, err := parseTime(value)
+		if err != nil {
+			return nil, err
+		}
+		return q.Where(column+" > ?", t), nil
+	}
+}
+
+// parseTime reads an RFC 3339 timestamp or a 2006-01-02 date.
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not a date", value)
+	}
+	return t, nil
+}
+
+// oneOf matches the SQL condition named by the value. Names missing from
+// conditions are rejected.
+func oneOf(conditions map[string]string) filter {
+	return func(q *pop.Query, value string) (*pop.Query, error) {
+		condition, ok := conditions[value]
+		if !ok {
+			return nil, fmt.Errorf("unknown value %q", value)
+		}
+		return q.Where(condition), nil
+	}
+}
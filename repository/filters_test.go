@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/gobuffalo/pop"
+)
+
+func Test_applySort(t *testing.T) {
+	columns := map[string]string{"name": "name", "city": "citi_name"}
+
+	if _, err := applySort(pop.Q(&pop.Connection{}), "name,-city", columns, "name"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := applySort(pop.Q(&pop.Connection{}), "password", columns, "name"); err == nil {
+		t.Fatal("expected an error for a field that is not whitelisted")
+	}
+}
+
+func Test_parseTime(t *testing.T) {
+	for _, value := range []string{"2020-03-01", "2020-03-01T10:00:00Z"} {
+		if _, err := parseTime(value); err != nil {
+			t.Errorf("%s: %v", value, err)
+		}
+	}
+	if _, err := parseTime("yesterday"); err == nil {
+		t.Error("expected an error for an invalid date")
+	}
+}
+
+func Test_oneOf(t *testing.T) {
+	f := oneOf(map[string]string{"no_city": "citi_name = ''"})
+
+	if _, err := f(pop.Q(&pop.Connection{}), "no_city"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f(pop.Q(&pop.Connection{}), "1 = 1"); err == nil {
+		t.Fatal("expected an error for a value that is not whitelisted")
+	}
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"fmt"
+	"location_service_v1/ls_v2/models"
+	"strings"
+
+	"github.com/gobuffalo/pop"
+	"github.com/gofrs/uuid"
+)
+
+// parseIncludes reads the comma separated "include" parameter. Only the
+// allowed associations are accepted.
+func parseIncludes(param string, allowed ...string) (map[string]bool, error) {
+	includes := map[string]bool{}
+	for _, name := range strings.Split(param, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		ok := false
+		for _, a := range allowed {
+			ok = ok || a == name
+		}
+		if !ok {
+			return nil, fmt.Errorf("include: unknown association %q", name)
+		}
+		includes[name] = true
+	}
+	return includes, nil
+}
+
+// loadPointCompanies sets the Company of every point with one query.
+// Points that already have their Company are skipped.
+func loadPointCompanies(tx *pop.Connection, points []models.Point) error {
+	ids := []uuid.UUID{}
+	seen := map[uuid.UUID]bool{}
+	for _, point := range points {
+		if point.Company == nil && point.CompanyID != uuid.Nil && !seen[point.CompanyID] {
+			seen[point.CompanyID] = true
+			ids = append(ids, point.CompanyID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	companies := models.Companies{}
+	if err := tx.Where("id IN (?)", ids).All(&companies); err != nil {
+		return err
+	}
+
+	byID := map[uuid.UUID]*models.Company{}
+	for i := range companies {
+		byID[companies[i].ID] = &companies[i]
+	}
+	for i := range points {
+		if points[i].Company == nil {
+			points[i].Company = byID[points[i].CompanyID]
+		}
+	}
+	return nil
+}
+
+// loadCompanyPoints sets the Points of every company with one query.
+func loadCompanyPoints(tx *pop.Connection, companies []models.Company) error {
+	if len(companies) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, len(companies))
+	for i, company := range companies {
+		ids[i] = company.ID
+	}
+
+	points := models.Points{}
+	if err := tx.Where("company_id IN (?)", ids).Order("name").All(&points); err != nil {
+		return err
+	}
+
+	byCompany := map[uuid.UUID][]models.Point{}
+	for _, point := range points {
+		byCompany[point.CompanyID] = append(byCompany[point.CompanyID], point)
+	}
+	for i := range companies {
+		companies[i].Points = byCompany[companies[i].ID]
+	}
+	return nil
+}
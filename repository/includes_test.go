@@ -0,0 +1,17 @@
+package repository
+
+import "testing"
+
+func Test_parseIncludes(t *testing.T) {
+	includes, err := parseIncludes("company, ", "company")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !includes["company"] {
+		t.Fatalf("expected company to be included, got %v", includes)
+	}
+
+	if _, err := parseIncludes("owner", "company"); err == nil {
+		t.Fatal("expected an error for an unknown association")
+	}
+}
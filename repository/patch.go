@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"location_service_v1/ls_v2/models"
+	"location_service_v1/ls_v2/patch"
+	"reflect"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/validate"
+)
+
+// ApplyPatch applies the body of a PATCH request to the JSON document doc
+// and decodes the result into v. The body is a JSON Patch for the
+// application/json-patch+json type and a JSON Merge Patch otherwise. Keys
+// that v does not have are rejected, and a value of the wrong type is a
+// validation error of its field.
+func ApplyPatch(c buffalo.Context, doc []byte, v interface{}) error {
+	body, err := ioutil.ReadAll(c.Request().Body)
+	if err != nil {
+		return models.BadRequestError(err)
+	}
+
+	patched, err := patch.Apply(doc, body, c.Request().Header.Get("Content-Type"))
+	if errors.Is(err, patch.ErrTestFailed) {
+		return models.ConflictError(err.Error())
+	}
+	if err != nil {
+		return models.BadRequestError(err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(patched))
+	dec.DisallowUnknownFields()
+	err = dec.Decode(v)
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		verrs := validate.NewErrors()
+		verrs.Add(typeErr.Field, fmt.Sprintf("%s must be a %s", typeErr.Field, typeErr.Type))
+		return models.ValidationError(verrs)
+	}
+	if err != nil {
+		return models.BadRequestError(fmt.Errorf("invalid patch: %v", err))
+	}
+	return nil
+}
+
+// patchModel applies the PATCH body of the request to model, a pointer to
+// a model struct, through its JSON form. The id, the timestamps, the
+// version and the associations are kept whatever the patch says.
+func patchModel(c buffalo.Context, model interface{}) error {
+	doc, err := json.Marshal(model)
+	if err != nil {
+		return err
+	}
+
+	v := reflect.ValueOf(model).Elem()
+	current := reflect.New(v.Type()).Elem()
+	current.Set(v)
+
+	// A key the patch removes is left at its zero value.
+	v.Set(reflect.Zero(v.Type()))
+	if err := ApplyPatch(c, doc, model); err != nil {
+		v.Set(current)
+		return err
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		switch v.Type().Field(i).Tag.Get("db") {
+		case "", "-", "id", "created_at", "updated_at", "version":
+			v.Field(i).Set(current.Field(i))
+		}
+	}
+	return nil
+}
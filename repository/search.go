@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// Markers put around the matched words by ts_headline. They are replaced
+// with <mark> tags after the snippet has been HTML escaped.
+const (
+	highlightStart = "[[mark]]"
+	highlightStop  = "[[/mark]]"
+)
+
+// prefixQuery turns free text into a tsquery that matches every word as a
+// prefix, so that fragments of an address still find the point.
+func prefixQuery(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+	return strings.Join(words, " & ")
+}
+
+// highlight escapes a ts_headline snippet and turns the markers into <mark>.
+func highlight(snippet string) string {
+	snippet = html.EscapeString(snippet)
+	snippet = strings.Replace(snippet, highlightStart, "<mark>", -1)
+	return strings.Replace(snippet, highlightStop, "</mark>", -1)
+}
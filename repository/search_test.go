@@ -0,0 +1,19 @@
+package repository
+
+import "testing"
+
+func Test_prefixQuery(t *testing.T) {
+	if got := prefixQuery("Москва, ул. Тверская 7"); got != "москва:* & ул:* & тверская:* & 7:*" {
+		t.Fatalf("unexpected query %q", got)
+	}
+	if got := prefixQuery(" '&|! "); got != "" {
+		t.Fatalf("expected an empty query, got %q", got)
+	}
+}
+
+func Test_highlight(t *testing.T) {
+	got := highlight("<b>" + highlightStart + "Tverskaya" + highlightStop)
+	if got != "&lt;b&gt;<mark>Tverskaya</mark>" {
+		t.Fatalf("unexpected snippet %q", got)
+	}
+}
@@ -0,0 +1,54 @@
+package rpc
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/pop"
+)
+
+// callContext is the buffalo.Context the services get for a gRPC call. It
+// carries the transaction as "tx" and the request fields as parameters,
+// which is all the repositories read. The other methods of
+// buffalo.Context are not available outside an HTTP handler.
+type callContext struct {
+	buffalo.Context
+	ctx    context.Context
+	params url.Values
+	data   map[string]interface{}
+	logger buffalo.Logger
+}
+
+func newCallContext(ctx context.Context, tx *pop.Connection, params url.Values, logger buffalo.Logger) *callContext {
+	if params == nil {
+		params = url.Values{}
+	}
+	return &callContext{
+		ctx:    ctx,
+		params: params,
+		data:   map[string]interface{}{"tx": tx},
+		logger: logger,
+	}
+}
+
+func (c *callContext) Deadline() (time.Time, bool) { return c.ctx.Deadline() }
+func (c *callContext) Done() <-chan struct{}       { return c.ctx.Done() }
+func (c *callContext) Err() error                  { return c.ctx.Err() }
+
+// Value returns what Set stored under key, or else the value of the
+// underlying context.
+func (c *callContext) Value(key interface{}) interface{} {
+	if k, ok := key.(string); ok {
+		if v, ok := c.data[k]; ok {
+			return v
+		}
+	}
+	return c.ctx.Value(key)
+}
+
+func (c *callContext) Set(key string, value interface{}) { c.data[key] = value }
+func (c *callContext) Params() buffalo.ParamValues       { return c.params }
+func (c *callContext) Param(key string) string           { return c.params.Get(key) }
+func (c *callContext) Logger() buffalo.Logger            { return c.logger }
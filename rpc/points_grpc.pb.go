@@ -0,0 +1,223 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: points.proto
+
+package rpc
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	PointService_GetPoint_FullMethodName           = "/ls.v2.PointService/GetPoint"
+	PointService_ListPoints_FullMethodName         = "/ls.v2.PointService/ListPoints"
+	PointService_StreamPointChanges_FullMethodName = "/ls.v2.PointService/StreamPointChanges"
+)
+
+// PointServiceClient is the client API for PointService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PointServiceClient interface {
+	GetPoint(ctx context.Context, in *GetPointRequest, opts ...grpc.CallOption) (*Point, error)
+	// ListPoints pages through the points newest first with the cursors of
+	// the JSON API.
+	ListPoints(ctx context.Context, in *ListPointsRequest, opts ...grpc.CallOption) (*ListPointsResponse, error)
+	// StreamPointChanges sends the change log of GET /points/changes from
+	// "since": the current state of each point created or updated and a
+	// tombstone for each point deleted. It then keeps sending the changes as
+	// they commit.
+	StreamPointChanges(ctx context.Context, in *StreamPointChangesRequest, opts ...grpc.CallOption) (PointService_StreamPointChangesClient, error)
+}
+
+type pointServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPointServiceClient(cc grpc.ClientConnInterface) PointServiceClient {
+	return &pointServiceClient{cc}
+}
+
+func (c *pointServiceClient) GetPoint(ctx context.Context, in *GetPointRequest, opts ...grpc.CallOption) (*Point, error) {
+	out := new(Point)
+	err := c.cc.Invoke(ctx, PointService_GetPoint_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pointServiceClient) ListPoints(ctx context.Context, in *ListPointsRequest, opts ...grpc.CallOption) (*ListPointsResponse, error) {
+	out := new(ListPointsResponse)
+	err := c.cc.Invoke(ctx, PointService_ListPoints_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pointServiceClient) StreamPointChanges(ctx context.Context, in *StreamPointChangesRequest, opts ...grpc.CallOption) (PointService_StreamPointChangesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &PointService_ServiceDesc.Streams[0], PointService_StreamPointChanges_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &pointServiceStreamPointChangesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type PointService_StreamPointChangesClient interface {
+	Recv() (*PointChange, error)
+	grpc.ClientStream
+}
+
+type pointServiceStreamPointChangesClient struct {
+	grpc.ClientStream
+}
+
+func (x *pointServiceStreamPointChangesClient) Recv() (*PointChange, error) {
+	m := new(PointChange)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// PointServiceServer is the server API for PointService service.
+// All implementations must embed UnimplementedPointServiceServer
+// for forward compatibility
+type PointServiceServer interface {
+	GetPoint(context.Context, *GetPointRequest) (*Point, error)
+	// ListPoints pages through the points newest first with the cursors of
+	// the JSON API.
+	ListPoints(context.Context, *ListPointsRequest) (*ListPointsResponse, error)
+	// StreamPointChanges sends the change log of GET /points/changes from
+	// "since": the current state of each point created or updated and a
+	// tombstone for each point deleted. It then keeps sending the changes as
+	// they commit.
+	StreamPointChanges(*StreamPointChangesRequest, PointService_StreamPointChangesServer) error
+	mustEmbedUnimplementedPointServiceServer()
+}
+
+// UnimplementedPointServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedPointServiceServer struct {
+}
+
+func (UnimplementedPointServiceServer) GetPoint(context.Context, *GetPointRequest) (*Point, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPoint not implemented")
+}
+func (UnimplementedPointServiceServer) ListPoints(context.Context, *ListPointsRequest) (*ListPointsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPoints not implemented")
+}
+func (UnimplementedPointServiceServer) StreamPointChanges(*StreamPointChangesRequest, PointService_StreamPointChangesServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamPointChanges not implemented")
+}
+func (UnimplementedPointServiceServer) mustEmbedUnimplementedPointServiceServer() {}
+
+// UnsafePointServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PointServiceServer will
+// result in compilation errors.
+type UnsafePointServiceServer interface {
+	mustEmbedUnimplementedPointServiceServer()
+}
+
+func RegisterPointServiceServer(s grpc.ServiceRegistrar, srv PointServiceServer) {
+	s.RegisterService(&PointService_ServiceDesc, srv)
+}
+
+func _PointService_GetPoint_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPointRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PointServiceServer).GetPoint(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PointService_GetPoint_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PointServiceServer).GetPoint(ctx, req.(*GetPointRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PointService_ListPoints_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPointsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PointServiceServer).ListPoints(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PointService_ListPoints_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PointServiceServer).ListPoints(ctx, req.(*ListPointsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PointService_StreamPointChanges_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamPointChangesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PointServiceServer).StreamPointChanges(m, &pointServiceStreamPointChangesServer{stream})
+}
+
+type PointService_StreamPointChangesServer interface {
+	Send(*PointChange) error
+	grpc.ServerStream
+}
+
+type pointServiceStreamPointChangesServer struct {
+	grpc.ServerStream
+}
+
+func (x *pointServiceStreamPointChangesServer) Send(m *PointChange) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// PointService_ServiceDesc is the grpc.ServiceDesc for PointService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PointService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "ls.v2.PointService",
+	HandlerType: (*PointServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetPoint",
+			Handler:    _PointService_GetPoint_Handler,
+		},
+		{
+			MethodName: "ListPoints",
+			Handler:    _PointService_ListPoints_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamPointChanges",
+			Handler:       _PointService_StreamPointChanges_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "points.proto",
+}
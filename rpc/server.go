@@ -0,0 +1,306 @@
+// Package rpc serves the PointService of points.proto over gRPC, for
+// backends that do not speak the JSON API. It calls the same services as
+// the HTTP handlers. points.pb.go and points_grpc.pb.go are generated:
+//
+//	protoc --go_out=. --go_opt=paths=source_relative \
+//		--go-grpc_out=. --go-grpc_opt=paths=source_relative points.proto
+package rpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative points.proto
+
+import (
+	"context"
+	"location_service_v1/ls_v2/models"
+	"location_service_v1/ls_v2/service"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/pop"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Tuning of StreamPointChanges.
+const (
+	pollInterval    = 2 * time.Second
+	changeBatchSize = 100
+)
+
+// Server is the PointService. NewGRPCServer serves it.
+type Server struct {
+	UnimplementedPointServiceServer
+	db               *pop.Connection
+	logger           buffalo.Logger
+	pointsService    *service.PointsService
+	apiTokensService *service.APITokensService
+}
+
+// NewServer is a
+func NewServer(db *pop.Connection, logger buffalo.Logger, pointsService *service.PointsService, apiTokensService *service.APITokensService) *Server {
+	return &Server{
+		db:               db,
+		logger:           logger,
+		pointsService:    pointsService,
+		apiTokensService: apiTokensService,
+	}
+}
+
+// NewGRPCServer is a gRPC server with s as its PointService. Every call
+// needs an API token in the "authorization: Bearer <token>" metadata, and
+// the errors of the services are answered with their gRPC status.
+func NewGRPCServer(s *Server, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(opts,
+		grpc.UnaryInterceptor(s.unaryInterceptor),
+		grpc.StreamInterceptor(s.streamInterceptor),
+	)
+	g := grpc.NewServer(opts...)
+	RegisterPointServiceServer(g, s)
+	return g
+}
+
+// unaryInterceptor authenticates a call and maps its error to a status.
+func (s *Server) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := s.authenticate(ctx); err != nil {
+		return nil, s.status(err)
+	}
+	res, err := handler(ctx, req)
+	if err != nil {
+		return nil, s.status(err)
+	}
+	return res, nil
+}
+
+// streamInterceptor authenticates a stream and maps its error to a status.
+func (s *Server) streamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := s.authenticate(ss.Context()); err != nil {
+		return s.status(err)
+	}
+	if err := handler(srv, ss); err != nil {
+		return s.status(err)
+	}
+	return nil
+}
+
+// authenticate checks the bearer token of the call metadata.
+func (s *Server) authenticate(ctx context.Context) error {
+	secret := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			secret = bearerToken(values[0])
+		}
+	}
+	if secret == "" {
+		return models.UnauthorizedError("missing bearer token")
+	}
+	return s.transaction(ctx, nil, func(c buffalo.Context) error {
+		_, err := s.apiTokensService.Authenticate(c, secret)
+		return err
+	})
+}
+
+// status is the gRPC status error of err. Internal errors are logged.
+func (s *Server) status(err error) error {
+	st, ok := statusOf(err)
+	if !ok {
+		s.logger.Error(err)
+	}
+	return st.Err()
+}
+
+// transaction runs fn with a context for the services, in a transaction
+// of its own.
+func (s *Server) transaction(ctx context.Context, params url.Values, fn func(c buffalo.Context) error) error {
+	return s.db.Transaction(func(tx *pop.Connection) error {
+		return fn(newCallContext(ctx, tx, params, s.logger))
+	})
+}
+
+// GetPoint gets one point.
+func (s *Server) GetPoint(ctx context.Context, req *GetPointRequest) (*Point, error) {
+	res := &Point{}
+	err := s.transaction(ctx, url.Values{"point_id": {req.Id}}, func(c buffalo.Context) error {
+		point, err := s.pointsService.Show(c)
+		if err != nil {
+			return err
+		}
+		points := models.Points{*point}
+		if req.IncludeCompany {
+			if err := s.pointsService.LoadCompanies(c, points); err != nil {
+				return err
+			}
+		}
+		res = newPoint(&points[0])
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ListPoints gets a page of points with the filters and cursors of the
+// JSON API.
+func (s *Server) ListPoints(ctx context.Context, req *ListPointsRequest) (*ListPointsResponse, error) {
+	params := url.Values{}
+	for key, value := range map[string]string{
+		"company_id": req.CompanyId,
+		"city":       req.City,
+		"name":       req.Name,
+		"problem":    req.Problem,
+		"parcel":     req.Parcel,
+		"after":      req.After,
+		"before":     req.Before,
+	} {
+		if value != "" {
+			params.Set(key, value)
+		}
+	}
+	if req.OwnerId != 0 {
+		params.Set("owner_id", strconv.FormatInt(req.OwnerId, 10))
+	}
+	if req.WeightG != 0 {
+		params.Set("weight_g", strconv.Itoa(int(req.WeightG)))
+	}
+	if req.Limit != 0 {
+		params.Set("limit", strconv.Itoa(int(req.Limit)))
+	}
+	if req.IncludeCompany {
+		params.Set("include", "company")
+	}
+
+	res := &ListPointsResponse{}
+	err := s.transaction(ctx, params, func(c buffalo.Context) error {
+		points, page, err := s.pointsService.Scroll(c)
+		if err != nil {
+			return err
+		}
+		for i := range *points {
+			res.Points = append(res.Points, newPoint(&(*points)[i]))
+		}
+		res.NextCursor = page.NextCursor
+		res.PrevCursor = page.PrevCursor
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// StreamPointChanges sends the change log of points from req.Since, batch
+// by batch, and then polls it until the client goes away. The last change
+// of each batch carries the token to resume the stream after it.
+func (s *Server) StreamPointChanges(req *StreamPointChangesRequest, stream PointService_StreamPointChangesServer) error {
+	ctx := stream.Context()
+	since := req.Since
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		params := url.Values{}
+		params.Set("since", since)
+		params.Set("limit", strconv.Itoa(changeBatchSize))
+
+		var feed *models.PointChangeFeed
+		err := s.transaction(ctx, params, func(c buffalo.Context) error {
+			var err error
+			feed, err = s.pointsService.Changes(c)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		changes := make([]*PointChange, 0, len(feed.Upserts)+len(feed.Deletions))
+		for i := range feed.Upserts {
+			changes = append(changes, &PointChange{
+				Type:    PointChange_UPSERTED,
+				Point:   newPoint(&feed.Upserts[i]),
+				PointId: feed.Upserts[i].ID.String(),
+			})
+		}
+		for _, d := range feed.Deletions {
+			changes = append(changes, &PointChange{
+				Type:      PointChange_DELETED,
+				PointId:   d.ID.String(),
+				DeletedAt: newTimestamp(d.DeletedAt),
+			})
+		}
+		if len(changes) > 0 {
+			changes[len(changes)-1].Next = feed.Next
+		}
+		for _, change := range changes {
+			if err := stream.Send(change); err != nil {
+				return nil // the client went away
+			}
+		}
+		since = feed.Next
+
+		if feed.HasMore {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// bearerToken is the secret of an "authorization: Bearer <secret>" header.
+func bearerToken(header string) string {
+	parts := strings.SplitN(strings.TrimSpace(header), " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(parts[1])
+}
+
+// newPoint is the message of a point.
+func newPoint(p *models.Point) *Point {
+	point := &Point{
+		Id:          p.ID.String(),
+		Name:        p.Name,
+		PointId:     int64(p.PointID),
+		Address:     p.Address,
+		City:        p.CityName,
+		Description: p.OutDescription,
+		OwnerId:     int64(p.OwnerID),
+		OwnerName:   p.OwnerName,
+		MaxLength:   int32(p.MaxLength),
+		MaxWidth:    int32(p.MaxWidth),
+		MaxHeight:   int32(p.MaxHeight),
+		MaxWeight:   int32(p.MaxWeight),
+		CompanyId:   p.CompanyID.String(),
+		CreatedAt:   newTimestamp(p.CreatedAt),
+		UpdatedAt:   newTimestamp(p.UpdatedAt),
+	}
+	if p.Company != nil {
+		point.Company = newCompany(p.Company)
+	}
+	return point
+}
+
+// newCompany is the message of a company.
+func newCompany(c *models.Company) *Company {
+	return &Company{
+		Id:        c.ID.String(),
+		Name:      c.Name,
+		CreatedAt: newTimestamp(c.CreatedAt),
+		UpdatedAt: newTimestamp(c.UpdatedAt),
+	}
+}
+
+// newTimestamp is t as a protobuf timestamp, nil when it is out of range.
+func newTimestamp(t time.Time) *timestamppb.Timestamp {
+	ts := timestamppb.New(t)
+	if ts.CheckValid() != nil {
+		return nil
+	}
+	return ts
+}
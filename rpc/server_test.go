@@ -0,0 +1,164 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"location_service_v1/ls_v2/models"
+	"location_service_v1/ls_v2/repository"
+	"location_service_v1/ls_v2/service"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/logger"
+	"github.com/gobuffalo/pop"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// dial serves s in memory and returns a client connected to it.
+func dial(t *testing.T, s *Server) PointServiceClient {
+	lis := bufconn.Listen(1 << 20)
+	g := NewGRPCServer(s)
+	go g.Serve(lis)
+	t.Cleanup(g.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return NewPointServiceClient(conn)
+}
+
+// withToken adds the bearer token metadata to ctx.
+func withToken(ctx context.Context, auth string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "authorization", auth)
+}
+
+func Test_Server_Unauthenticated(t *testing.T) {
+	client := dial(t, NewServer(nil, logger.New(logger.ErrorLevel), nil, nil))
+	ctx := context.Background()
+
+	for _, auth := range []string{"", "Basic x", "Bearer "} {
+		_, err := client.GetPoint(withToken(ctx, auth), &GetPointRequest{Id: "1"})
+		if status.Code(err) != codes.Unauthenticated {
+			t.Errorf("GetPoint with %q: got %v", auth, err)
+		}
+	}
+
+	stream, err := client.StreamPointChanges(ctx, &StreamPointChangesRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("StreamPointChanges: got %v", err)
+	}
+}
+
+func Test_Server_RoundTrip(t *testing.T) {
+	db, err := pop.Connect("test")
+	if err == nil {
+		err = db.RawQuery("SELECT 1").Exec()
+	}
+	if err != nil {
+		t.Skipf("no test database: %v", err)
+	}
+
+	token, secret, err := models.NewAPIToken("rpc test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	point := &models.Point{Name: "Tverskaya 7", CityName: "Moscow"}
+	if err := db.Create(token); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(point); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Destroy(point)
+		db.Destroy(token)
+	})
+
+	pointsService := service.NewPointsService(repository.NewPointsRepository(), repository.NewWebhooksRepository(), repository.NewEventsRepository())
+	apiTokensService := service.NewAPITokensService(repository.NewAPITokensRepository())
+	client := dial(t, NewServer(db, logger.New(logger.ErrorLevel), pointsService, apiTokensService))
+	ctx := withToken(context.Background(), "Bearer "+secret)
+
+	got, err := client.GetPoint(ctx, &GetPointRequest{Id: point.ID.String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Id != point.ID.String() || got.City != "Moscow" {
+		t.Errorf("got %v", got)
+	}
+
+	list, err := client.ListPoints(ctx, &ListPointsRequest{City: "Moscow", Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Points) != 1 {
+		t.Errorf("got %v", list)
+	}
+
+	_, err = client.GetPoint(ctx, &GetPointRequest{Id: "00000000-0000-0000-0000-000000000000"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("unknown point: got %v", err)
+	}
+
+	streamCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	stream, err := client.StreamPointChanges(streamCtx, &StreamPointChangesRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// next receives the changes until the one of the point
+	next := func() *PointChange {
+		for {
+			change, err := stream.Recv()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if change.PointId == point.ID.String() {
+				return change
+			}
+		}
+	}
+
+	if change := next(); change.Type != PointChange_UPSERTED || change.Point.Name != "Tverskaya 7" {
+		t.Errorf("got %v", change)
+	}
+	if err := db.Destroy(point); err != nil {
+		t.Fatal(err)
+	}
+	if change := next(); change.Type != PointChange_DELETED || change.DeletedAt == nil {
+		t.Errorf("got %v", change)
+	}
+}
+
+func Test_StatusOf(t *testing.T) {
+	s, ok := statusOf(models.NotFoundError("point", fmt.Errorf("sql: no rows")))
+	if !ok || s.Code() != codes.NotFound || s.Message() != "point not found" {
+		t.Errorf("got %v, %v", s, ok)
+	}
+
+	s, ok = statusOf(status.Error(codes.InvalidArgument, "invalid since"))
+	if !ok || s.Code() != codes.InvalidArgument {
+		t.Errorf("got %v, %v", s, ok)
+	}
+
+	s, ok = statusOf(fmt.Errorf("connection refused"))
+	if ok || s.Code() != codes.Internal || s.Message() != "internal error" {
+		t.Errorf("got %v, %v", s, ok)
+	}
+}
+
+var _ buffalo.Context = (*callContext)(nil)
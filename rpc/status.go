@@ -0,0 +1,37 @@
+package rpc
+
+import (
+	"errors"
+	"location_service_v1/ls_v2/models"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// statusCodes are the gRPC codes of the domain errors.
+var statusCodes = map[models.ErrorCode]codes.Code{
+	models.CodeBadRequest:         codes.InvalidArgument,
+	models.CodeUnauthorized:       codes.Unauthenticated,
+	models.CodeNotFound:           codes.NotFound,
+	models.CodeConflict:           codes.Aborted,
+	models.CodeInvalid:            codes.InvalidArgument,
+	models.CodeUpstream:           codes.Unavailable,
+	models.CodePreconditionFailed: codes.FailedPrecondition,
+	models.CodeRateLimited:        codes.ResourceExhausted,
+	models.CodeKeyReused:          codes.InvalidArgument,
+}
+
+// statusOf maps err to the status sent to the client. The second result is
+// false for internal errors, whose details are not shown.
+func statusOf(err error) (*status.Status, bool) {
+	if s, ok := status.FromError(err); ok {
+		return s, true
+	}
+	var e *models.Error
+	if errors.As(err, &e) {
+		if code, ok := statusCodes[e.Code]; ok {
+			return status.New(code, e.Message), true
+		}
+	}
+	return status.New(codes.Internal, "internal error"), false
+}
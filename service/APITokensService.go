@@ -0,0 +1,24 @@
+package service
+
+import (
+	"github.com/gobuffalo/buffalo"
+	"location_service_v1/ls_v2/models"
+	"location_service_v1/ls_v2/repository"
+)
+
+// APITokensService is a
+type APITokensService struct {
+	apiTokensRepository *repository.APITokensRepository
+}
+
+// NewAPITokensService is a
+func NewAPITokensService(repository *repository.APITokensRepository) *APITokensService {
+	return &APITokensService{
+		apiTokensRepository: repository,
+	}
+}
+
+// Authenticate finds the APIToken of a bearer secret
+func (s *APITokensService) Authenticate(c buffalo.Context, secret string) (*models.APIToken, error) {
+	return s.apiTokensRepository.Authenticate(c, secret)
+}
@@ -4,19 +4,23 @@ import (
 	"github.com/gobuffalo/buffalo"
 	"github.com/gobuffalo/pop"
 	"github.com/gobuffalo/validate"
+	"location_service_v1/ls_v2/dto"
 	"location_service_v1/ls_v2/models"
 	"location_service_v1/ls_v2/repository"
 )
 
-// CompaniesService is a
+// CompaniesService is a. Its changes of companies fire the company
+// webhooks.
 type CompaniesService struct {
 	companiesRepository *repository.CompaniesRepository
+	webhooksRepository  *repository.WebhooksRepository
 }
 
 // NewCompaniesService is a
-func NewCompaniesService(repository *repository.CompaniesRepository) *CompaniesService {
+func NewCompaniesService(repository *repository.CompaniesRepository, webhooksRepository *repository.WebhooksRepository) *CompaniesService {
 	return &CompaniesService{
 		companiesRepository: repository,
+		webhooksRepository:  webhooksRepository,
 	}
 }
 
@@ -31,6 +35,16 @@ func (s *CompaniesService) List(c buffalo.Context) (*models.Companies, *pop.Quer
 	return companies, q, err
 }
 
+// All gets every Company ordered by name
+func (s *CompaniesService) All(c buffalo.Context) (*models.Companies, error) {
+	return s.companiesRepository.All(c)
+}
+
+// Scroll is a
+func (s *CompaniesService) Scroll(c buffalo.Context) (*models.Companies, *repository.CursorPage, error) {
+	return s.companiesRepository.Scroll(c)
+}
+
 // Show gets the data for one Company. This function is mapped to
 // the path GET /companies/{company_id}
 func (s *CompaniesService) Show(c buffalo.Context) (*models.Company, error) {
@@ -41,6 +55,21 @@ func (s *CompaniesService) Show(c buffalo.Context) (*models.Company, error) {
 	return company, err
 }
 
+// LoadPoints sets the Points of the companies
+func (s *CompaniesService) LoadPoints(c buffalo.Context, companies []models.Company) error {
+	return s.companiesRepository.LoadPoints(c, companies)
+}
+
+// Find gets the Company of the path without the list parameters
+func (s *CompaniesService) Find(c buffalo.Context) (*models.Company, error) {
+	return s.companiesRepository.Find(c)
+}
+
+// CountPoints counts the Points of the company
+func (s *CompaniesService) CountPoints(c buffalo.Context) (int, error) {
+	return s.companiesRepository.CountPoints(c)
+}
+
 // New renders the form for creating a new Company.
 // This function is mapped to the path GET /companies/new
 func (s *CompaniesService) New(c buffalo.Context) *models.Company {
@@ -54,9 +83,30 @@ func (s *CompaniesService) Create(c buffalo.Context) (*validate.Errors, *models.
 	if err != nil {
 		return nil, nil, err
 	}
+	if err := s.notify(c, models.EventCompanyCreated, create, company); err != nil {
+		return nil, nil, err
+	}
 	return create, company, nil
 }
 
+// Insert adds an already filled Company to the DB
+func (s *CompaniesService) Insert(c buffalo.Context, company *models.Company) (*validate.Errors, error) {
+	verrs, err := s.companiesRepository.Insert(c, company)
+	if err != nil {
+		return nil, err
+	}
+	return verrs, s.notify(c, models.EventCompanyCreated, verrs, company)
+}
+
+// Save updates an already filled Company in the DB
+func (s *CompaniesService) Save(c buffalo.Context, company *models.Company) (*validate.Errors, error) {
+	verrs, err := s.companiesRepository.Save(c, company)
+	if err != nil {
+		return nil, err
+	}
+	return verrs, s.notify(c, models.EventCompanyUpdated, verrs, company)
+}
+
 // Edit renders a edit form for a Company. This function is
 // mapped to the path GET /companies/{company_id}/edit
 func (s *CompaniesService) Edit(c buffalo.Context) (*models.Company, error) {
@@ -72,17 +122,57 @@ func (s *CompaniesService) Edit(c buffalo.Context) (*models.Company, error) {
 func (s *CompaniesService) Update(c buffalo.Context) (*validate.Errors, *models.Company, error) {
 	update, company, err := s.companiesRepository.Update(c)
 	if err != nil {
+		return nil, company, err
+	}
+	if err := s.notify(c, models.EventCompanyUpdated, update, company); err != nil {
 		return nil, nil, err
 	}
 	return update, company, nil
 }
 
+// Patch changes only the fields of a Company that the body sends. This
+// function is mapped to the path PATCH /companies/{company_id}
+func (s *CompaniesService) Patch(c buffalo.Context) (*validate.Errors, *models.Company, error) {
+	verrs, company, err := s.companiesRepository.Patch(c)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.notify(c, models.EventCompanyUpdated, verrs, company); err != nil {
+		return nil, nil, err
+	}
+	return verrs, company, nil
+}
+
 // Destroy deletes a Company from the DB. This function is mapped
-// to the path DELETE /companies/{company_id}
+// to the path DELETE /companies/{company_id}. Its points are kept
+// without a company and fire point.updated.
 func (s *CompaniesService) Destroy(c buffalo.Context) (*models.Company, error) {
 	company, err := s.companiesRepository.Destroy(c)
 	if err != nil {
 		return nil, err
 	}
+	if err := s.notify(c, models.EventCompanyDeleted, nil, company); err != nil {
+		return nil, err
+	}
+
+	// The points are kept without a company
+	points, err := s.companiesRepository.ReleasePoints(c)
+	if err != nil {
+		return nil, err
+	}
+	subscriptions, err := s.webhooksRepository.ActiveSubscriptions(c)
+	if err != nil {
+		return nil, err
+	}
+	for _, point := range *points {
+		if err := s.webhooksRepository.Enqueue(c, subscriptions, models.EventPointUpdated, dto.NewPoint(point)); err != nil {
+			return nil, err
+		}
+	}
 	return company, nil
 }
+
+// notify queues the webhooks of a change of company.
+func (s *CompaniesService) notify(c buffalo.Context, event string, verrs *validate.Errors, company *models.Company) error {
+	return notify(c, s.webhooksRepository, event, verrs, dto.NewCompany(*company))
+}
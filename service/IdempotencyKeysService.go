@@ -0,0 +1,44 @@
+package service
+
+import (
+	"location_service_v1/ls_v2/models"
+	"location_service_v1/ls_v2/repository"
+	"time"
+)
+
+// IdempotencyKeysService is a
+type IdempotencyKeysService struct {
+	idempotencyKeysRepository *repository.IdempotencyKeysRepository
+	ttl                       time.Duration
+}
+
+// NewIdempotencyKeysService is a. The responses are replayed for ttl.
+func NewIdempotencyKeysService(repository *repository.IdempotencyKeysRepository, ttl time.Duration) *IdempotencyKeysService {
+	return &IdempotencyKeysService{
+		idempotencyKeysRepository: repository,
+		ttl:                       ttl,
+	}
+}
+
+// Begin takes the key of client for the request to route with hash
+func (s *IdempotencyKeysService) Begin(client, key, route, hash string) (*models.IdempotencyKey, bool, error) {
+	now := time.Now()
+	return s.idempotencyKeysRepository.Begin(&models.IdempotencyKey{
+		Client:      client,
+		Key:         key,
+		Route:       route,
+		RequestHash: hash,
+		ExpiresAt:   now.Add(s.ttl),
+		CreatedAt:   now,
+	})
+}
+
+// Finish stores the response of a key
+func (s *IdempotencyKeysService) Finish(key *models.IdempotencyKey) error {
+	return s.idempotencyKeysRepository.Finish(key)
+}
+
+// Release frees a key for a retry
+func (s *IdempotencyKeysService) Release(key *models.IdempotencyKey) error {
+	return s.idempotencyKeysRepository.Release(key)
+}
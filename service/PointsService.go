@@ -1,22 +1,31 @@
 package service
 
 import (
+	"encoding/json"
+
 	"github.com/gobuffalo/buffalo"
 	"github.com/gobuffalo/pop"
 	"github.com/gobuffalo/validate"
+	"github.com/gofrs/uuid"
+	"location_service_v1/ls_v2/dto"
 	"location_service_v1/ls_v2/models"
 	"location_service_v1/ls_v2/repository"
 )
 
-// PointsService is a
+// PointsService is a. Its changes of points fire the point webhooks and
+// live events.
 type PointsService struct {
-	pointsRepository *repository.PointsRepository
+	pointsRepository   *repository.PointsRepository
+	webhooksRepository *repository.WebhooksRepository
+	eventsRepository   *repository.EventsRepository
 }
 
 // NewPointsService is a
-func NewPointsService(repository *repository.PointsRepository) *PointsService {
+func NewPointsService(repository *repository.PointsRepository, webhooksRepository *repository.WebhooksRepository, eventsRepository *repository.EventsRepository) *PointsService {
 	return &PointsService{
-		pointsRepository: repository,
+		pointsRepository:   repository,
+		webhooksRepository: webhooksRepository,
+		eventsRepository:   eventsRepository,
 	}
 }
 
@@ -31,6 +40,21 @@ func (s *PointsService) List(c buffalo.Context) (*models.Points, *pop.Query, err
 	return points, q, err
 }
 
+// Search finds Points by the words of the "q" parameter
+func (s *PointsService) Search(c buffalo.Context) (*models.PointSearchResults, *pop.Query, error) {
+	return s.pointsRepository.Search(c)
+}
+
+// Autocomplete suggests cities, addresses or point names
+func (s *PointsService) Autocomplete(c buffalo.Context) (*models.Suggestions, error) {
+	return s.pointsRepository.Autocomplete(c)
+}
+
+// Scroll is a
+func (s *PointsService) Scroll(c buffalo.Context) (*models.Points, *repository.CursorPage, error) {
+	return s.pointsRepository.Scroll(c)
+}
+
 // Show gets the data for one Point. This function is mapped to
 // the path GET /points/{point_id}
 func (s *PointsService) Show(c buffalo.Context) (*models.Point, error) {
@@ -41,6 +65,21 @@ func (s *PointsService) Show(c buffalo.Context) (*models.Point, error) {
 	return point, err
 }
 
+// Export passes all the Points matching the filters of List to fn in batches
+func (s *PointsService) Export(c buffalo.Context, fn func(models.Points) error) error {
+	return s.pointsRepository.Export(c, fn)
+}
+
+// LoadCompanies sets the Company of the points that do not have it yet
+func (s *PointsService) LoadCompanies(c buffalo.Context, points []models.Point) error {
+	return s.pointsRepository.LoadCompanies(c, points)
+}
+
+// Changes gets a page of the change feed of points
+func (s *PointsService) Changes(c buffalo.Context) (*models.PointChangeFeed, error) {
+	return s.pointsRepository.Changes(c)
+}
+
 // New renders the form for creating a new Point.
 // This function is mapped to the path GET /points/new
 func (s *PointsService) New(c buffalo.Context) *models.Point {
@@ -54,9 +93,43 @@ func (s *PointsService) Create(c buffalo.Context) (*validate.Errors, *models.Poi
 	if err != nil {
 		return nil, nil, err
 	}
+	if err := s.notify(c, models.EventPointCreated, create, point); err != nil {
+		return nil, nil, err
+	}
 	return create, point, nil
 }
 
+// CreateForCompany adds a Point of the given Company to the DB. This
+// function is mapped to the path POST /companies/{company_id}/points
+func (s *PointsService) CreateForCompany(c buffalo.Context, companyID uuid.UUID) (*validate.Errors, *models.Point, error) {
+	create, point, err := s.pointsRepository.CreateForCompany(c, companyID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.notify(c, models.EventPointCreated, create, point); err != nil {
+		return nil, nil, err
+	}
+	return create, point, nil
+}
+
+// Insert adds an already filled Point to the DB
+func (s *PointsService) Insert(c buffalo.Context, point *models.Point) (*validate.Errors, error) {
+	verrs, err := s.pointsRepository.Insert(c, point)
+	if err != nil {
+		return nil, err
+	}
+	return verrs, s.notify(c, models.EventPointCreated, verrs, point)
+}
+
+// Save updates an already filled Point in the DB
+func (s *PointsService) Save(c buffalo.Context, point *models.Point) (*validate.Errors, error) {
+	verrs, err := s.pointsRepository.Save(c, point)
+	if err != nil {
+		return nil, err
+	}
+	return verrs, s.notify(c, models.EventPointUpdated, verrs, point)
+}
+
 // Edit renders a edit form for a Point. This function is
 // mapped to the path GET /points/{point_id}/edit
 func (s *PointsService) Edit(c buffalo.Context) (*models.Point, error) {
@@ -72,11 +145,27 @@ func (s *PointsService) Edit(c buffalo.Context) (*models.Point, error) {
 func (s *PointsService) Update(c buffalo.Context) (*validate.Errors, *models.Point, error) {
 	update, point, err := s.pointsRepository.Update(c)
 	if err != nil {
+		return nil, point, err
+	}
+	if err := s.notify(c, models.EventPointUpdated, update, point); err != nil {
 		return nil, nil, err
 	}
 	return update, point, nil
 }
 
+// Patch changes only the fields of a Point that the body sends. This
+// function is mapped to the path PATCH /points/{point_id}
+func (s *PointsService) Patch(c buffalo.Context) (*validate.Errors, *models.Point, error) {
+	verrs, point, err := s.pointsRepository.Patch(c)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.notify(c, models.EventPointUpdated, verrs, point); err != nil {
+		return nil, nil, err
+	}
+	return verrs, point, nil
+}
+
 // Destroy deletes a Point from the DB. This function is mapped
 // to the path DELETE /points/{point_id}
 func (s *PointsService) Destroy(c buffalo.Context) (*models.Point, error) {
@@ -84,15 +173,99 @@ func (s *PointsService) Destroy(c buffalo.Context) (*models.Point, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := s.notify(c, models.EventPointDeleted, nil, point); err != nil {
+		return nil, err
+	}
 	return point, nil
 }
 
+// Bulk creates, updates and deletes Points in one request, with decode to
+// read their points. This function is mapped to the path
+// POST /api/v1/points/bulk
+func (s *PointsService) Bulk(c buffalo.Context, bulk *models.PointsBulk, decode func(json.RawMessage, *models.Point) error) (models.PointResults, error) {
+	results, err := s.pointsRepository.Bulk(c, bulk, decode)
+	if err != nil {
+		return nil, err
+	}
+
+	// A rolled back atomic batch takes its deliveries with it.
+	subscriptions, err := s.webhooksRepository.ActiveSubscriptions(c)
+	if err != nil {
+		return nil, err
+	}
+	events := map[string]string{
+		models.BulkCreate: models.EventPointCreated,
+		models.BulkUpdate: models.EventPointUpdated,
+		models.BulkDelete: models.EventPointDeleted,
+	}
+	for _, result := range results {
+		if result.Err != nil {
+			continue
+		}
+		if err := s.notifyTo(c, subscriptions, events[result.Op], result.Point); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
 func (s *PointsService) PickPointsList(c buffalo.Context) ([]*models.Point, error) {
-	points, err := s.pointsRepository.PickPointsList(c)
+	// The progress is sent at once: the points are only seen by others
+	// once the whole import commits.
+	points, err := s.pointsRepository.PickPointsList(c, func(run models.ImportRun) {
+		event, err := models.NewLiveEvent(models.EventImportProgress, uuid.Nil, "", run)
+		if err == nil {
+			err = s.eventsRepository.PublishNow(event)
+		}
+		if err != nil {
+			c.Logger().Error(err)
+		}
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	// the points the import rejected were not given an id
+	subscriptions, err := s.webhooksRepository.ActiveSubscriptions(c)
+	if err != nil {
+		return nil, err
+	}
+	for _, point := range points {
+		if point.ID == uuid.Nil {
+			continue
+		}
+		if err := s.notifyTo(c, subscriptions, models.EventPointCreated, point); err != nil {
+			return nil, err
+		}
+	}
+
 	return points, err
 
 }
+
+// notify queues the webhooks and the live event of a change of point.
+func (s *PointsService) notify(c buffalo.Context, event string, verrs *validate.Errors, point *models.Point) error {
+	if verrs != nil && verrs.HasAny() {
+		return nil
+	}
+	subscriptions, err := s.webhooksRepository.ActiveSubscriptions(c)
+	if err != nil {
+		return err
+	}
+	return s.notifyTo(c, subscriptions, event, point)
+}
+
+// notifyTo is notify with the subscriptions that a request changing many
+// points loaded once.
+func (s *PointsService) notifyTo(c buffalo.Context, subscriptions models.WebhookSubscriptions, event string, point *models.Point) error {
+	data := dto.NewPoint(*point)
+	if err := s.webhooksRepository.Enqueue(c, subscriptions, event, data); err != nil {
+		return err
+	}
+
+	live, err := models.NewLiveEvent(event, point.CompanyID, point.CityName, data)
+	if err != nil {
+		return err
+	}
+	return s.eventsRepository.Publish(c, live)
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"github.com/gobuffalo/buffalo"
+	"location_service_v1/ls_v2/models"
+	"location_service_v1/ls_v2/repository"
+	"time"
+)
+
+// StatsService is a
+type StatsService struct {
+	statsRepository *repository.StatsRepository
+}
+
+// NewStatsService is a
+func NewStatsService(repository *repository.StatsRepository) *StatsService {
+	return &StatsService{
+		statsRepository: repository,
+	}
+}
+
+// Points counts Points grouped by company and/or city. This function is
+// mapped to the path GET /stats/points
+func (s *StatsService) Points(c buffalo.Context) (*models.PointStats, time.Time, error) {
+	return s.statsRepository.Points(c)
+}
+
+// Dashboard gets the overview of the home page. This function is mapped to
+// the path GET /
+func (s *StatsService) Dashboard(c buffalo.Context) (*models.Dashboard, error) {
+	return s.statsRepository.Dashboard(c)
+}
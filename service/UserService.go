@@ -31,6 +31,11 @@ func (s *UsersService) List(c buffalo.Context) (*models.Users, *pop.Query, error
 	return users, q, err
 }
 
+// Scroll is a
+func (s *UsersService) Scroll(c buffalo.Context) (*models.Users, *repository.CursorPage, error) {
+	return s.usersRepository.Scroll(c)
+}
+
 // Show gets the data for one Company. This function is mapped to
 // the path GET /users/{user_id}
 func (s *UsersService) Show(c buffalo.Context) (*models.User, error) {
@@ -57,6 +62,16 @@ func (s *UsersService) Create(c buffalo.Context) (*validate.Errors, *models.User
 	return create, user, nil
 }
 
+// Insert adds an already filled User to the DB
+func (s *UsersService) Insert(c buffalo.Context, user *models.User) (*validate.Errors, error) {
+	return s.usersRepository.Insert(c, user)
+}
+
+// Save updates an already filled User in the DB
+func (s *UsersService) Save(c buffalo.Context, user *models.User) (*validate.Errors, error) {
+	return s.usersRepository.Save(c, user)
+}
+
 // Edit renders a edit form for a Company. This function is
 // mapped to the path GET /users/{user_id}/edit
 func (s *UsersService) Edit(c buffalo.Context) (*models.User, error) {
@@ -70,11 +85,13 @@ func (s *UsersService) Edit(c buffalo.Context) (*models.User, error) {
 // Update changes a Company in the DB. This function is mapped to
 // the path PUT /users/{user_id}
 func (s *UsersService) Update(c buffalo.Context) (*validate.Errors, *models.User, error) {
-	update, user, err := s.usersRepository.Update(c)
-	if err != nil {
-		return nil, nil, err
-	}
-	return update, user, nil
+	return s.usersRepository.Update(c)
+}
+
+// Patch changes only the fields of a User that the body sends. This
+// function is mapped to the path PATCH /users/{user_id}
+func (s *UsersService) Patch(c buffalo.Context) (*validate.Errors, *models.User, error) {
+	return s.usersRepository.Patch(c)
 }
 
 // Destroy deletes a Company from the DB. This function is mapped
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/pop"
+	"github.com/gobuffalo/validate"
+	"location_service_v1/ls_v2/models"
+	"location_service_v1/ls_v2/repository"
+)
+
+// WebhooksService is a
+type WebhooksService struct {
+	webhooksRepository *repository.WebhooksRepository
+}
+
+// NewWebhooksService is a
+func NewWebhooksService(repository *repository.WebhooksRepository) *WebhooksService {
+	return &WebhooksService{
+		webhooksRepository: repository,
+	}
+}
+
+// List gets the WebhookSubscriptions
+func (s *WebhooksService) List(c buffalo.Context) (*models.WebhookSubscriptions, *pop.Query, error) {
+	return s.webhooksRepository.List(c)
+}
+
+// Show gets one WebhookSubscription
+func (s *WebhooksService) Show(c buffalo.Context) (*models.WebhookSubscription, error) {
+	return s.webhooksRepository.Show(c)
+}
+
+// Deliveries gets the delivery log of a WebhookSubscription
+func (s *WebhooksService) Deliveries(c buffalo.Context) (*models.WebhookDeliveries, *pop.Query, error) {
+	return s.webhooksRepository.Deliveries(c)
+}
+
+// Create adds a WebhookSubscription
+func (s *WebhooksService) Create(c buffalo.Context) (*validate.Errors, *models.WebhookSubscription, error) {
+	return s.webhooksRepository.Create(c)
+}
+
+// Update changes a WebhookSubscription
+func (s *WebhooksService) Update(c buffalo.Context) (*validate.Errors, *models.WebhookSubscription, error) {
+	return s.webhooksRepository.Update(c)
+}
+
+// Destroy deletes a WebhookSubscription
+func (s *WebhooksService) Destroy(c buffalo.Context) (*models.WebhookSubscription, error) {
+	return s.webhooksRepository.Destroy(c)
+}
+
+// Resend queues a delivery again
+func (s *WebhooksService) Resend(c buffalo.Context) (*models.WebhookDelivery, error) {
+	return s.webhooksRepository.Resend(c)
+}
+
+// notify queues the webhooks of an event unless verrs rejected the change.
+// data is the changed record in its API form.
+func notify(c buffalo.Context, webhooks *repository.WebhooksRepository, event string, verrs *validate.Errors, data interface{}) error {
+	if verrs != nil && verrs.HasAny() {
+		return nil
+	}
+	subscriptions, err := webhooks.ActiveSubscriptions(c)
+	if err != nil {
+		return err
+	}
+	return webhooks.Enqueue(c, subscriptions, event, data)
+}
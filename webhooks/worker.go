@@ -0,0 +1,179 @@
+// Package webhooks sends the webhook deliveries that the changes of points
+// and companies queue. Deliveries are rows of webhook_deliveries, so they
+// survive restarts and several workers can share them.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"location_service_v1/ls_v2/models"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/pop"
+	"github.com/gofrs/uuid"
+)
+
+// Tuning of the Worker. A claimed delivery is hidden from the other
+// workers for leaseDuration, which outlasts the sends of a whole batch; a
+// worker that dies mid-batch leaves its deliveries to be sent again once
+// the lease is over.
+const (
+	pollInterval  = 5 * time.Second
+	batchSize     = 20
+	sendTimeout   = 10 * time.Second
+	leaseDuration = 5 * time.Minute
+)
+
+// Worker polls for the pending deliveries that are due and sends them.
+type Worker struct {
+	db     *pop.Connection
+	logger buffalo.Logger
+	client *http.Client
+}
+
+// NewWorker is a. Its client only connects to public addresses, so that a
+// subscription whose host resolves to an internal one gets nothing.
+func NewWorker(db *pop.Connection, logger buffalo.Logger) *Worker {
+	dialer := &net.Dialer{Timeout: sendTimeout, Control: dialPublic}
+	return &Worker{
+		db:     db,
+		logger: logger,
+		client: &http.Client{
+			Timeout: sendTimeout,
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: sendTimeout,
+				MaxIdleConns:        batchSize,
+				IdleConnTimeout:     90 * time.Second,
+			},
+		},
+	}
+}
+
+// Run sends deliveries until ctx is done. A full batch is followed by the
+// next one right away, otherwise the worker waits for pollInterval.
+func (w *Worker) Run(ctx context.Context) {
+	for {
+		n, err := w.work(ctx)
+		if err != nil {
+			w.logger.Error(err)
+		}
+		if n == batchSize && err == nil {
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// work claims one batch of due deliveries of active subscriptions and
+// sends them. No transaction is open while they are sent: each result is
+// saved on its own.
+func (w *Worker) work(ctx context.Context) (int, error) {
+	deliveries, err := w.claim(time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	subscriptions := map[uuid.UUID]*models.WebhookSubscription{}
+	for i := range deliveries {
+		if ctx.Err() != nil {
+			break // the lease of the rest runs out
+		}
+		d := &deliveries[i]
+		s, ok := subscriptions[d.SubscriptionID]
+		if !ok {
+			s = &models.WebhookSubscription{}
+			if err := w.db.Find(s, d.SubscriptionID); err != nil {
+				return len(deliveries), err
+			}
+			subscriptions[d.SubscriptionID] = s
+		}
+
+		w.send(ctx, s, d, time.Now())
+		err := w.db.Transaction(func(tx *pop.Connection) error {
+			return tx.Update(d)
+		})
+		if err != nil {
+			return len(deliveries), err
+		}
+	}
+	return len(deliveries), nil
+}
+
+// claim leases the due deliveries of a batch by moving their
+// next_attempt_at past the lease. Rows claimed by other workers at the
+// same time are skipped.
+func (w *Worker) claim(now time.Time) (models.WebhookDeliveries, error) {
+	deliveries := models.WebhookDeliveries{}
+	err := w.db.Transaction(func(tx *pop.Connection) error {
+		return tx.RawQuery(`UPDATE webhook_deliveries SET next_attempt_at = ?
+			WHERE id IN (SELECT id FROM webhook_deliveries
+				WHERE status = ? AND next_attempt_at <= ?
+				AND subscription_id IN (SELECT id FROM webhook_subscriptions WHERE active)
+				ORDER BY next_attempt_at LIMIT ? FOR UPDATE SKIP LOCKED)
+			RETURNING *`,
+			now.Add(leaseDuration), models.DeliveryPending, now, batchSize).All(&deliveries)
+	})
+	return deliveries, err
+}
+
+// dialPublic refuses to connect to an address that webhooks must not
+// reach, see models.PublicIP. It runs after the host is resolved, so it
+// also catches the names that resolve to such an address.
+func dialPublic(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !models.PublicIP(ip) {
+		return fmt.Errorf("%s is not a public address", host)
+	}
+	return nil
+}
+
+// send posts the payload of d to the subscription and records the result
+// in d. Any 2xx response counts as delivered.
+func (w *Worker) send(ctx context.Context, s *models.WebhookSubscription, d *models.WebhookDelivery, now time.Time) {
+	body := []byte(d.Payload)
+	req, err := http.NewRequest(http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		d.Failed(0, err, now)
+		return
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ls_v2-webhooks")
+	req.Header.Set("X-Webhook-Event", d.Event)
+	req.Header.Set("X-Webhook-Delivery", d.ID.String())
+	req.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set("X-Webhook-Signature", models.SignWebhook(s.Secret, now, body))
+
+	res, err := w.client.Do(req)
+	if err != nil {
+		d.Failed(0, err, now)
+		return
+	}
+	io.Copy(ioutil.Discard, io.LimitReader(res.Body, 64<<10))
+	res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		d.Failed(res.StatusCode, fmt.Errorf("unexpected status %s", res.Status), now)
+		return
+	}
+	d.Delivered(res.StatusCode, now)
+}
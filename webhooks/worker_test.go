@@ -0,0 +1,67 @@
+package webhooks
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"location_service_v1/ls_v2/models"
+
+	"github.com/gobuffalo/logger"
+	"github.com/gofrs/uuid"
+)
+
+func Test_Worker_Send(t *testing.T) {
+	now := time.Unix(1586340000, 0)
+	s := &models.WebhookSubscription{ID: uuid.Must(uuid.NewV4()), Secret: "whsec_test"}
+	d, err := models.NewWebhookDelivery(s.ID, models.EventPointCreated, map[string]string{"name": "A"}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	status := http.StatusNoContent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if string(body) != d.Payload {
+			t.Errorf("got body %s, want %s", body, d.Payload)
+		}
+		if got := r.Header.Get("X-Webhook-Event"); got != models.EventPointCreated {
+			t.Errorf("got event %q", got)
+		}
+		if got, want := r.Header.Get("X-Webhook-Signature"), models.SignWebhook(s.Secret, now, body); got != want {
+			t.Errorf("got signature %q, want %q", got, want)
+		}
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+	s.URL = srv.URL
+
+	w := NewWorker(nil, logger.New(logger.ErrorLevel))
+
+	// the test server is on a loopback address, which the worker refuses
+	w.send(context.Background(), s, d, now)
+	if d.Attempts != 1 || d.ResponseStatus != 0 || !strings.Contains(d.Error.String, "not a public address") {
+		t.Errorf("a loopback target got %s", d)
+	}
+	d.Attempts = 0
+	w.client = srv.Client()
+
+	status = http.StatusInternalServerError
+	w.send(context.Background(), s, d, now)
+	if d.Status != models.DeliveryPending || d.Attempts != 1 || d.ResponseStatus != 500 || !d.Error.Valid {
+		t.Errorf("after a 500 got %s", d)
+	}
+	if !d.NextAttemptAt.Equal(now.Add(models.RetryDelay)) {
+		t.Errorf("got next attempt at %v", d.NextAttemptAt)
+	}
+
+	status = http.StatusNoContent
+	w.send(context.Background(), s, d, now)
+	if d.Status != models.DeliveryDelivered || d.Attempts != 2 || d.ResponseStatus != 204 || d.Error.Valid {
+		t.Errorf("after a 204 got %s", d)
+	}
+}